@@ -0,0 +1,186 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+	"github.com/co0p/4dc/examples/pomodoro/internal/control"
+	"github.com/co0p/4dc/examples/pomodoro/internal/paths"
+	"github.com/co0p/4dc/examples/pomodoro/internal/status"
+)
+
+// runStart implements `pomodoro start [--task LABEL]`.
+func runStart(args []string) int {
+	fs := newFlagSet("start")
+	task := fs.String("task", "", "label the pomodoro with a task")
+	if !parseFlags(fs, "usage: pomodoro start [--task LABEL]", args) {
+		return 2
+	}
+	return withClient(func(c *control.Client) (status.Status, error) {
+		return c.Start(string(app.KindPomodoro), *task)
+	})
+}
+
+// runBreak implements `pomodoro break [--long|--short]`.
+func runBreak(args []string) int {
+	fs := newFlagSet("break")
+	long := fs.Bool("long", false, "start a long break")
+	short := fs.Bool("short", false, "start a short break")
+	if !parseFlags(fs, "usage: pomodoro break [--long|--short]", args) {
+		return 2
+	}
+	kind := app.KindBreak
+	switch {
+	case *long && *short:
+		fmt.Fprintln(os.Stderr, "pomodoro: --long and --short are mutually exclusive")
+		return 2
+	case *long:
+		kind = string(app.KindLongBreak)
+	case *short:
+		kind = string(app.KindShortBreak)
+	}
+	return withClient(func(c *control.Client) (status.Status, error) {
+		return c.Start(kind, "")
+	})
+}
+
+// runPause implements `pomodoro pause`.
+func runPause(args []string) int {
+	return simpleClientCommand("pause", args, (*control.Client).Pause)
+}
+
+// runResume implements `pomodoro resume`.
+func runResume(args []string) int {
+	return simpleClientCommand("resume", args, (*control.Client).Resume)
+}
+
+// runStop implements `pomodoro stop`.
+func runStop(args []string) int {
+	return simpleClientCommand("stop", args, (*control.Client).Stop)
+}
+
+// statusUsage is the synopsis of the status subcommand.
+const statusUsage = "usage: pomodoro status [--format text|json|waybar|tmux|template=TEXT] [--watch]"
+
+// runStatus implements `pomodoro status`.
+func runStatus(args []string) int {
+	fs := newFlagSet("status")
+	spec := fs.String("format", status.FormatText, "output format")
+	watch := fs.Bool("watch", false, "print a line whenever the output changes")
+	if !parseFlags(fs, statusUsage, args) {
+		return 2
+	}
+	format, err := status.ParseFormat(*spec)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pomodoro: %v\n%s\n", err, statusUsage)
+		return 2
+	}
+
+	return connect(func(c *control.Client) error {
+		if !*watch {
+			st, err := c.Status()
+			if err != nil {
+				return err
+			}
+			return printFormatted(format, st)
+		}
+		// status bars only need a line when the output changes
+		var last string
+		return c.Watch(func(st status.Status) error {
+			line, err := format(st)
+			if err != nil || line == last {
+				return err
+			}
+			last = line
+			fmt.Println(line)
+			return nil
+		})
+	})
+}
+
+// printFormatted prints st rendered by format.
+func printFormatted(format status.Formatter, st status.Status) error {
+	line, err := format(st)
+	if err != nil {
+		return err
+	}
+	fmt.Println(line)
+	return nil
+}
+
+// simpleClientCommand runs a client command without arguments.
+func simpleClientCommand(name string, args []string, fn func(*control.Client) (status.Status, error)) int {
+	if !parseFlags(newFlagSet(name), "usage: pomodoro "+name, args) {
+		return 2
+	}
+	return withClient(fn)
+}
+
+// withClient connects to the running app, calls fn and prints the
+// resulting status.
+func withClient(fn func(*control.Client) (status.Status, error)) int {
+	return connect(func(c *control.Client) error {
+		st, err := fn(c)
+		if err != nil {
+			return err
+		}
+		fmt.Println(status.Text(st))
+		return nil
+	})
+}
+
+// connect connects to the running app and calls fn. It reports failures
+// on stderr and returns the exit status.
+func connect(fn func(*control.Client) error) int {
+	path, err := paths.SocketFile()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pomodoro: %v\n", err)
+		return 1
+	}
+	c, err := control.Dial(path)
+	if errors.Is(err, control.ErrNotRunning) {
+		fmt.Fprintln(os.Stderr, "pomodoro: no running instance; start the app with `pomodoro` first")
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pomodoro: %v\n", err)
+		return 1
+	}
+	defer c.Close()
+
+	if err := fn(c); err != nil {
+		fmt.Fprintf(os.Stderr, "pomodoro: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// newFlagSet returns a flag set for a subcommand. Errors are reported
+// by `parseFlags`.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {}
+	return fs
+}
+
+// parseFlags parses args into fs and rejects positional arguments. On
+// failure it prints the problem and synopsis to stderr.
+func parseFlags(fs *flag.FlagSet, synopsis string, args []string) bool {
+	err := fs.Parse(args)
+	if err == nil && fs.NArg() > 0 {
+		err = fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	if err != nil {
+		if err != flag.ErrHelp {
+			fmt.Fprintf(os.Stderr, "pomodoro: %v\n", err)
+		}
+		fmt.Fprintln(os.Stderr, synopsis)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// command is a subcommand of the pomodoro binary. run receives the
+// arguments after the command name and returns the process exit code.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
+}
+
+// commands lists the subcommands in the order `pomodoro -h` shows them.
+var commands = []command{
+	{"start", "start a pomodoro in the running app", runStart},
+	{"break", "start a break in the running app", runBreak},
+	{"pause", "pause the running session", runPause},
+	{"resume", "resume the paused session", runResume},
+	{"stop", "abandon the active session without counting it", runStop},
+	{"status", "print the state of the running app", runStatus},
+	{"stats", "summarise the session history", runStats},
+	{"export", "export completed sessions as CSV, JSON or iCalendar", runExport},
+	{"config", "check, print or locate the configuration file", runConfig},
+}
+
+// dispatch runs the subcommand name with args.
+func dispatch(name string, args []string) int {
+	for _, c := range commands {
+		if c.name == name {
+			return c.run(args)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "pomodoro: unknown command %q\n\n", name)
+	usage()
+	return 2
+}
+
+// usage prints the flags and subcommands to stderr.
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "usage: pomodoro [flags] [command [args]]\n\n")
+	fmt.Fprintf(out, "Without a command the timer runs in the system tray.\n\ncommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(out, "  %-8s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintf(out, "\nflags:\n")
+	flag.PrintDefaults()
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+	"github.com/co0p/4dc/examples/pomodoro/internal/apptest"
+	"github.com/co0p/4dc/examples/pomodoro/internal/config"
+	"github.com/co0p/4dc/examples/pomodoro/internal/control"
+	"github.com/co0p/4dc/examples/pomodoro/internal/paths"
+)
+
+// run runs dispatch with its output captured.
+func run(t *testing.T, args ...string) (code int, stdout, stderr string) {
+	t.Helper()
+	dir := t.TempDir()
+	outFile, err := os.Create(filepath.Join(dir, "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer outFile.Close()
+	errFile, err := os.Create(filepath.Join(dir, "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer errFile.Close()
+
+	oldOut, oldErr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = outFile, errFile
+	code = dispatch(args[0], args[1:])
+	os.Stdout, os.Stderr = oldOut, oldErr
+
+	out, err := os.ReadFile(outFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	errOut, err := os.ReadFile(errFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code, string(out), string(errOut)
+}
+
+// serveApp points the per-user directories at temporary ones and serves
+// a fresh app on the control socket found there.
+func serveApp(t *testing.T) app.App {
+	t.Helper()
+	t.Setenv(config.PathEnv, "")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	// keep the socket path short: Unix socket paths are limited to about
+	// 100 bytes
+	runtime, err := os.MkdirTemp("", "pomo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(runtime) })
+	t.Setenv("XDG_RUNTIME_DIR", runtime)
+	path, err := paths.SocketFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, _ := apptest.NewApp(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- control.NewServer(a).ListenAndServe(ctx, path) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("serve: %v", err)
+		}
+	})
+	apptest.Eventually(t, "the control socket", func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	})
+	return a
+}
+
+func TestDispatch(t *testing.T) {
+	a := serveApp(t)
+
+	// the steps run in order against the same app
+	for _, tc := range []struct {
+		args   []string
+		code   int
+		state  app.State
+		kind   app.Kind
+		stdout string
+		stderr string
+	}{
+		{args: []string{"start", "--task", "ABC-1"}, state: app.StatePomodoroRunning, kind: app.KindPomodoro, stdout: "ABC-1"},
+		{args: []string{"pause"}, state: app.StatePaused, kind: app.KindPomodoro, stdout: "paused"},
+		{args: []string{"resume"}, state: app.StatePomodoroRunning, kind: app.KindPomodoro},
+		{args: []string{"status", "--format", "json"}, state: app.StatePomodoroRunning, kind: app.KindPomodoro, stdout: `"state":"PomodoroRunning"`},
+		{args: []string{"break", "--long"}, state: app.StateBreakRunning, kind: app.KindLongBreak},
+		{args: []string{"stop"}, state: app.StateIdle},
+		{args: []string{"break", "--short"}, state: app.StateBreakRunning, kind: app.KindShortBreak},
+		{args: []string{"stop"}, state: app.StateIdle},
+		{args: []string{"break"}, state: app.StateBreakRunning, kind: app.KindShortBreak},
+		{args: []string{"stop"}, state: app.StateIdle},
+		{args: []string{"status"}, state: app.StateIdle, stdout: "idle, cycle 0/4"},
+		{args: []string{"stats"}, state: app.StateIdle, stdout: "this week"},
+		{args: []string{"export", "--format", "json"}, state: app.StateIdle, stdout: "[]"},
+		{args: []string{"config", "path"}, state: app.StateIdle, stdout: "config.json"},
+
+		{args: []string{"break", "--long", "--short"}, code: 2, state: app.StateIdle, stderr: "--long and --short are mutually exclusive"},
+		{args: []string{"pause", "now"}, code: 2, state: app.StateIdle, stderr: `unexpected argument "now"`},
+		{args: []string{"start", "--bogus"}, code: 2, state: app.StateIdle, stderr: "usage: pomodoro start"},
+		{args: []string{"nap"}, code: 2, state: app.StateIdle, stderr: "unknown command \"nap\"\n\nusage: pomodoro"},
+	} {
+		code, stdout, stderr := run(t, tc.args...)
+		if code != tc.code {
+			t.Fatalf("%v: exit code %d, want %d (stderr %q)", tc.args, code, tc.code, stderr)
+		}
+		if !strings.Contains(stdout, tc.stdout) || !strings.Contains(stderr, tc.stderr) {
+			t.Fatalf("%v: stdout %q, stderr %q; want them to contain %q and %q", tc.args, stdout, stderr, tc.stdout, tc.stderr)
+		}
+		if a.State() != tc.state || a.Kind() != tc.kind {
+			t.Fatalf("%v: app is %s/%q, want %s/%q", tc.args, a.State(), a.Kind(), tc.state, tc.kind)
+		}
+	}
+}
+
+func TestClientCommandsWithoutInstance(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	for _, name := range []string{"start", "break", "pause", "resume", "stop", "status"} {
+		code, _, stderr := run(t, name)
+		if code != 1 || !strings.Contains(stderr, "no running instance") {
+			t.Errorf("%s: exit code %d, stderr %q; want 1 and no running instance", name, code, stderr)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/config"
+)
+
+const configUsage = `usage: pomodoro config check|print|path
+
+  check  validate the configuration file and environment overrides
+  print  print the effective configuration as a documented file
+  path   print the location of the configuration file
+`
+
+// runConfig implements the `config` subcommand and returns the process
+// exit code.
+func runConfig(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprint(os.Stderr, configUsage)
+		return 2
+	}
+
+	path, err := config.Path()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pomodoro: %v\n", err)
+		return 1
+	}
+
+	switch args[0] {
+	case "path":
+		fmt.Println(path)
+	case "check":
+		if _, err := config.Load(path); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Printf("%s: ok\n", path)
+	case "print":
+		c, err := config.Load(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		os.Stdout.Write(config.Format(c))
+	default:
+		fmt.Fprint(os.Stderr, configUsage)
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/export"
+)
+
+const exportUsage = `usage: pomodoro export [--format csv|json|ics] [--since DATE] [--until DATE]
+
+Write completed sessions from the history to stdout. DATE is a local date
+such as 2025-12-01, or an RFC 3339 time; --until includes the whole day.
+`
+
+// runExport implements the `export` subcommand and returns the process
+// exit code.
+func runExport(args []string) int {
+	fs := newFlagSet("export")
+	format := fs.String("format", "csv", "output format: csv, json or ics")
+	since := fs.String("since", "", "first day to export")
+	until := fs.String("until", "", "last day to export")
+	if !parseFlags(fs, exportUsage, args) {
+		return 2
+	}
+
+	f, err := export.ParseFormat(*format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pomodoro: %v\n", err)
+		return 2
+	}
+	from, err := parseDate(*since, false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pomodoro: --since: %v\n", err)
+		return 2
+	}
+	to, err := parseDate(*until, true)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pomodoro: --until: %v\n", err)
+		return 2
+	}
+
+	recs, err := loadHistory()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pomodoro: %v\n", err)
+		return 1
+	}
+	w := bufio.NewWriter(os.Stdout)
+	if err := export.Write(w, f, export.Filter(recs, from, to), time.Local); err != nil {
+		fmt.Fprintf(os.Stderr, "pomodoro: %v\n", err)
+		return 1
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "pomodoro: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// parseDate parses a local date or an RFC 3339 time. For an end bound a
+// date means the end of that day. An empty string is an open bound.
+func parseDate(s string, end bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("want a date like 2025-12-01, got %q", s)
+	}
+	if end {
+		d = d.AddDate(0, 0, 1)
+	}
+	return d, nil
+}
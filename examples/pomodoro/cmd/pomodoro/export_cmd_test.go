@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	}
+	for _, tc := range []struct {
+		in   string
+		end  bool
+		want time.Time
+	}{
+		{"", false, time.Time{}},
+		{"", true, time.Time{}},
+		{"2025-12-01", false, day(2025, 12, 1)},
+		// an end date includes the whole day: the bound is the next midnight
+		{"2025-12-31", true, day(2026, 1, 1)},
+		{"2025-12-05T09:30:00Z", false, time.Date(2025, 12, 5, 9, 30, 0, 0, time.UTC)},
+		{"2025-12-05T09:30:00Z", true, time.Date(2025, 12, 5, 9, 30, 0, 0, time.UTC)},
+	} {
+		got, err := parseDate(tc.in, tc.end)
+		if err != nil {
+			t.Errorf("parseDate(%q, %t): %v", tc.in, tc.end, err)
+			continue
+		}
+		if !got.Equal(tc.want) {
+			t.Errorf("parseDate(%q, %t) = %s, want %s", tc.in, tc.end, got, tc.want)
+		}
+	}
+
+	until, _ := parseDate("2025-12-31", true)
+	if last := time.Date(2025, 12, 31, 23, 59, 59, 0, time.Local); !last.Before(until) {
+		t.Errorf("--until 2025-12-31 excludes %s", last)
+	}
+
+	for _, in := range []string{"12/01/2025", "2025-13-01", "yesterday"} {
+		if _, err := parseDate(in, false); err == nil {
+			t.Errorf("parseDate(%q) succeeded, want an error", in)
+		}
+	}
+}
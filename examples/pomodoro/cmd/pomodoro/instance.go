@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+	"github.com/co0p/4dc/examples/pomodoro/internal/control"
+	"github.com/co0p/4dc/examples/pomodoro/internal/instance"
+	"github.com/co0p/4dc/examples/pomodoro/internal/paths"
+	"github.com/co0p/4dc/examples/pomodoro/internal/status"
+)
+
+// forwardTimeout bounds waiting for the control socket of an instance
+// that holds the lock but may still be starting up.
+const forwardTimeout = 3 * time.Second
+
+// claimInstance takes the instance lock and returns a function releasing
+// it. When another instance holds the lock, the request of this launch
+// is forwarded to it and the process exits. Without a usable runtime
+// directory the app runs unlocked.
+func claimInstance() (release func()) {
+	path, err := paths.LockFile()
+	if err != nil {
+		log.Printf("single-instance lock disabled: %v", err)
+		return func() {}
+	}
+	l, err := instance.Acquire(path)
+	var locked *instance.LockedError
+	if errors.As(err, &locked) {
+		os.Exit(forwardToOwner(locked))
+	}
+	if err != nil {
+		log.Printf("single-instance lock disabled: %v", err)
+		return func() {}
+	}
+	return func() {
+		if err := l.Release(); err != nil {
+			log.Printf("releasing instance lock: %v", err)
+		}
+	}
+}
+
+// forwardToOwner hands the request of this launch to the instance
+// holding the lock: `--start` starts a pomodoro there, otherwise its
+// status is printed.
+func forwardToOwner(locked *instance.LockedError) int {
+	path, err := paths.SocketFile()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pomodoro: %v\n", err)
+		return 1
+	}
+	c, err := dialOwner(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pomodoro: %v, but it does not answer on %s: %v\n", locked, path, err)
+		return 1
+	}
+	defer c.Close()
+
+	var st status.Status
+	if *flagStart {
+		st, err = c.Start(string(app.KindPomodoro), "")
+	} else {
+		st, err = c.Status()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pomodoro: %v\n", err)
+		return 1
+	}
+	if locked.PID == 0 {
+		fmt.Printf("already running: %s\n", status.Text(st))
+	} else {
+		fmt.Printf("already running (pid %d): %s\n", locked.PID, status.Text(st))
+	}
+	return 0
+}
+
+// dialOwner connects to the control socket at path, retrying for a
+// short while in case the owner has taken the lock but not yet opened
+// the socket.
+func dialOwner(path string) (*control.Client, error) {
+	deadline := time.Now().Add(forwardTimeout)
+	for {
+		c, err := control.Dial(path)
+		if err == nil || time.Now().After(deadline) {
+			return c, err
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
@@ -4,8 +4,12 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"os/exec"
+	"runtime"
+	"strings"
 	"time"
 
 	"os/signal"
@@ -13,15 +17,29 @@ import (
 
 	"github.com/co0p/4dc/examples/pomodoro/assets"
 	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+	"github.com/co0p/4dc/examples/pomodoro/internal/clock"
+	"github.com/co0p/4dc/examples/pomodoro/internal/config"
+	"github.com/co0p/4dc/examples/pomodoro/internal/control"
+	"github.com/co0p/4dc/examples/pomodoro/internal/history"
+	"github.com/co0p/4dc/examples/pomodoro/internal/hooks"
+	"github.com/co0p/4dc/examples/pomodoro/internal/httpapi"
+	"github.com/co0p/4dc/examples/pomodoro/internal/notify"
+	"github.com/co0p/4dc/examples/pomodoro/internal/paths"
+	"github.com/co0p/4dc/examples/pomodoro/internal/status"
 	"github.com/co0p/4dc/examples/pomodoro/internal/tray"
+	"github.com/co0p/4dc/examples/pomodoro/internal/webhook"
 )
 
 var (
-	flagVersion = flag.Bool("version", false, "print version and exit")
-	flagSmoke   = flag.Bool("smoke", false, "run smoke startup and exit")
+	flagVersion   = flag.Bool("version", false, "print version and exit")
+	flagSmoke     = flag.Bool("smoke", false, "run smoke startup and exit")
+	flagNoRestore = flag.Bool("no-restore", false, "start idle instead of restoring the session that was active at exit")
+	flagStart     = flag.Bool("start", false, "start a pomodoro right away, in the running instance if there is one")
+	flagUI        = flag.String("ui", tray.DefaultBackend, "user interface: "+strings.Join(tray.Backends(), ", "))
 )
 
 func main() {
+	flag.Usage = usage
 	flag.Parse()
 
 	if *flagVersion {
@@ -29,12 +47,54 @@ func main() {
 		return
 	}
 
+	// without a subcommand the app runs in the tray
+	if flag.NArg() > 0 {
+		os.Exit(dispatch(flag.Arg(0), flag.Args()[1:]))
+	}
+	runTray()
+}
+
+// runTray runs the timer with the user interface chosen by --ui until it
+// is quit or the process receives SIGINT or SIGTERM.
+func runTray() {
 	// simple human-friendly logger
 	log.SetFlags(log.LstdFlags | log.Lmsgprefix)
 	log.SetPrefix("pomodoro: ")
 
-	// use short durations for local demo default; domain durations are configurable
-	a := app.New(25*time.Minute, 5*time.Minute)
+	cfgPath, err := config.Path()
+	if err != nil {
+		log.Fatalf("config: %v", err)
+	}
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		log.Fatalf("invalid configuration (see `pomodoro config check`):\n%v", err)
+	}
+
+	opts := []app.Option{
+		app.WithSettings(appSettings(cfg)),
+		app.WithLogger(log.Default()),
+	}
+	// record finished sessions in the local history log
+	var store history.Store
+	if path, err := paths.HistoryFile(); err != nil {
+		log.Printf("history disabled: %v", err)
+	} else {
+		store = history.NewFileStore(path)
+		opts = append(opts, app.WithHistory(store))
+	}
+	a, err := app.NewWithOptions(opts...)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	t, err := tray.New(*flagUI, a, tray.Options{
+		Icon:  assets.Icon(),
+		Title: titleSettings(cfg),
+		In:    os.Stdin,
+		Out:   os.Stdout,
+	})
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
 
 	log.Println("starting application")
 
@@ -50,8 +110,51 @@ func main() {
 		return
 	}
 
-	// construct tray (no icon for now)
-	t := tray.NewSystray(a, assets.Icon())
+	// only one instance drives the timer; a second launch hands its
+	// request to the running one
+	release := claimInstance()
+	defer release()
+
+	// run the user's scripts on state changes, including the restore below
+	if dir, err := paths.HooksDir(); err != nil {
+		log.Printf("hooks disabled: %v", err)
+	} else {
+		r := hooks.New(dir, hooks.Options{Timeout: cfg.HookTimeout, Concurrency: cfg.HookConcurrency})
+		defer r.Attach(a)()
+	}
+
+	if len(cfg.Webhooks) > 0 {
+		defer startWebhooks(a, cfg.Webhooks)()
+	}
+	if cfg.NotifyBackend != notify.BackendNone {
+		defer startNotifications(a, cfg)()
+	}
+
+	// bring back the session that was active when the app last stopped and
+	// keep the state file current from now on
+	if path, err := paths.StateFile(); err != nil {
+		log.Printf("session restore disabled: %v", err)
+	} else {
+		defer restoreSession(a, path)()
+	}
+	if *flagStart {
+		a.StartPomodoro()
+	}
+
+	// let tools that only read files follow the session
+	if path, err := paths.StatusFile(); err != nil {
+		log.Printf("status file disabled: %v", err)
+	} else {
+		defer status.PublishFile(a, path, clock.Real(), status.FileInterval)()
+	}
+
+	// the terminal front-end prints the log above its status line
+	if w, ok := t.(io.Writer); ok {
+		log.SetOutput(w)
+	}
+	if rs, ok := t.(tray.RecentTaskSeeder); ok && store != nil {
+		rs.SeedRecentTasks(recentTasks(store))
+	}
 
 	// handle OS signals for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
@@ -65,9 +168,182 @@ func main() {
 		cancel()
 	}()
 
-	if err := t.Run(ctx); err != nil {
+	// opt-in control API for scripts and editor plugins
+	if cfg.HTTPPort != 0 {
+		startHTTPAPI(ctx, a, cfg.HTTPPort)
+	}
+
+	// let `pomodoro start`, `status` and friends reach this instance
+	startControlServer(ctx, a)
+
+	// re-apply configuration changes without restarting
+	go config.NewWatcher(cfgPath, clock.Real(), config.DefaultPollInterval,
+		func(c config.Config) { applyConfig(a, t, c) },
+		func(err error) { log.Printf("config reload rejected:\n%v", err) },
+	).Run(ctx)
+
+	// a UI stopped by a signal reports the cancellation; that is a clean exit
+	if err := t.Run(ctx); err != nil && ctx.Err() == nil {
 		log.Printf("tray.Run error: %v", err)
+		release()
 		os.Exit(1)
 	}
 	log.Println("exited")
 }
+
+// restoreSession restores the snapshot stored at path into a unless
+// restoring was disabled on the command line, and keeps the file current
+// from then on. The file is kept current before restoring so it records
+// how a session that ran out while the app was not running ended;
+// otherwise every launch would complete it again. The returned function
+// stops keeping the file current.
+func restoreSession(a app.App, path string) (stop func()) {
+	stop = app.PersistSnapshots(a, path)
+	if *flagNoRestore {
+		log.Println("session restore skipped")
+		return stop
+	}
+	snap, err := app.ReadSnapshotFile(path)
+	if err != nil {
+		log.Printf("session restore failed: %v", err)
+		return stop
+	}
+	if err := a.Restore(snap); err != nil {
+		log.Printf("session restore failed: %v", err)
+		return stop
+	}
+	if snap.State != app.StateIdle {
+		log.Printf("restored %s session (%s)", snap.Kind, snap.State)
+	}
+	return stop
+}
+
+// recentTasks returns the task labels most recently used in store.
+func recentTasks(store history.Store) []string {
+	recs, err := store.Load()
+	if err != nil {
+		// malformed lines are skipped; the valid records are still used
+		log.Printf("history: %v", err)
+	}
+	return history.RecentLabels(recs, 5)
+}
+
+// startHTTPAPI serves the HTTP control API on 127.0.0.1:port until ctx
+// is cancelled.
+func startHTTPAPI(ctx context.Context, a app.App, port int) {
+	path, err := paths.TokenFile()
+	if err != nil {
+		log.Printf("http api disabled: %v", err)
+		return
+	}
+	token, err := httpapi.LoadToken(path)
+	if err != nil {
+		log.Printf("http api disabled: %v", err)
+		return
+	}
+	go func() {
+		log.Printf("http api listening on 127.0.0.1:%d (token in %s)", port, path)
+		if err := httpapi.New(a, token).ListenAndServe(ctx, port); err != nil {
+			log.Printf("http api: %v", err)
+		}
+	}()
+}
+
+// startNotifications announces completed sessions with the notification
+// backend chosen in cfg and returns a function stopping notifications.
+func startNotifications(a app.App, cfg config.Config) (stop func()) {
+	backend := cfg.NotifyBackend
+	if backend == notify.BackendAuto {
+		backend = notify.Detect(runtime.GOOS, exec.LookPath)
+	}
+	n, err := notify.New(backend)
+	if err != nil {
+		log.Printf("notifications disabled: %v", err)
+		return func() {}
+	}
+	pomodoro, err := notify.ParseMessage(cfg.PomodoroDone.Title, cfg.PomodoroDone.Body)
+	if err != nil {
+		log.Printf("notifications disabled: %v", err)
+		return func() {}
+	}
+	brk, err := notify.ParseMessage(cfg.BreakDone.Title, cfg.BreakDone.Body)
+	if err != nil {
+		log.Printf("notifications disabled: %v", err)
+		return func() {}
+	}
+	log.Printf("notifications via %s", backend)
+	return notify.Attach(a, n, notify.Options{Pomodoro: pomodoro, Break: brk})
+}
+
+// startWebhooks delivers state changes to the configured webhooks and
+// returns a function stopping delivery.
+func startWebhooks(a app.App, hooks []config.Webhook) (stop func()) {
+	targets := make([]webhook.Target, len(hooks))
+	for i, h := range hooks {
+		targets[i] = webhook.Target{URL: h.URL, Secret: h.Secret}
+	}
+	var o webhook.Options
+	if path, err := paths.WebhookQueueFile(); err != nil {
+		log.Printf("webhook queue kept in memory: %v", err)
+	} else {
+		o.QueueFile = path
+	}
+	s := webhook.New(targets, o)
+	if n := s.Pending(); n > 0 {
+		log.Printf("webhook: %d deliveries queued from the last run", n)
+	}
+	return s.Attach(a)
+}
+
+// startControlServer serves the control socket until ctx is cancelled.
+func startControlServer(ctx context.Context, a app.App) {
+	path, err := paths.SocketFile()
+	if err != nil {
+		log.Printf("control socket disabled: %v", err)
+		return
+	}
+	go func() {
+		if err := control.NewServer(a).ListenAndServe(ctx, path); err != nil {
+			log.Printf("control socket: %v", err)
+		}
+	}()
+}
+
+// appSettings maps the user configuration onto the timer settings. It
+// is used at startup and on every reload, so it sets every field: a
+// reload must not reset settings the configuration does not cover.
+func appSettings(c config.Config) app.Settings {
+	return app.Settings{
+		Pomodoro:       c.Pomodoro,
+		ShortBreak:     c.ShortBreak,
+		LongBreak:      c.LongBreak,
+		CycleLength:    c.CycleLength,
+		CycleIdleReset: app.DefaultCycleIdleReset,
+		AutoAdvance:    app.AutoAdvanceOff,
+	}
+}
+
+// titleSettings maps the user configuration onto the tray title settings.
+func titleSettings(c config.Config) tray.TitleSettings {
+	return tray.TitleSettings{Format: c.TitleFormat, Interval: c.TickInterval}
+}
+
+// applyConfig applies a reloaded configuration to the running app and
+// tray. The active session keeps its end time. The configuration is
+// checked completely first, so a rejected reload changes nothing.
+func applyConfig(a app.App, t tray.Tray, c config.Config) {
+	ts := titleSettings(c)
+	if err := ts.Validate(); err != nil {
+		log.Printf("config reload rejected: %v", err)
+		return
+	}
+	a.UpdateSettings(appSettings(c))
+	if tc, ok := t.(tray.TitleConfigurer); ok {
+		if err := tc.ApplyTitleSettings(ts); err != nil {
+			log.Printf("config reload: %v", err)
+			return
+		}
+	}
+	log.Printf("config reloaded: pomodoro=%s short=%s long=%s cycle=%d",
+		c.Pomodoro, c.ShortBreak, c.LongBreak, c.CycleLength)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+	"github.com/co0p/4dc/examples/pomodoro/internal/apptest"
+	"github.com/co0p/4dc/examples/pomodoro/internal/history"
+)
+
+func TestRestoreSessionCompletesAnEndedSessionOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	// the app stopped during a pomodoro that ran out five minutes ago
+	snap := app.Snapshot{
+		State:   app.StatePomodoroRunning,
+		Kind:    app.KindPomodoro,
+		Started: apptest.Epoch.Add(-30 * time.Minute),
+		Planned: app.DefaultPomodoro,
+		End:     apptest.Epoch.Add(-5 * time.Minute),
+	}
+	if err := app.WriteSnapshotFile(path, snap); err != nil {
+		t.Fatal(err)
+	}
+
+	store := history.NewMemoryStore()
+	for launch := 1; launch <= 2; launch++ {
+		a, _ := apptest.NewApp(t, app.WithHistory(store))
+		stop := restoreSession(a, path)
+		if err := a.Shutdown(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		stop()
+	}
+
+	recs, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 1 || recs[0].Outcome != history.OutcomeCompleted {
+		t.Fatalf("expected one completed pomodoro after two launches, got %+v", recs)
+	}
+}
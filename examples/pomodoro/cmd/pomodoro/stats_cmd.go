@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/history"
+	"github.com/co0p/4dc/examples/pomodoro/internal/paths"
+	"github.com/co0p/4dc/examples/pomodoro/internal/stats"
+)
+
+const statsUsage = `usage: pomodoro stats
+
+Print today's, this week's and this month's totals from the session history.
+`
+
+// runStats implements the `stats` subcommand and returns the process exit
+// code.
+func runStats(args []string) int {
+	if len(args) != 0 {
+		fmt.Fprint(os.Stderr, statsUsage)
+		return 2
+	}
+	recs, err := loadHistory()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pomodoro: %v\n", err)
+		return 1
+	}
+	printStats(os.Stdout, stats.Compute(recs, time.Local), time.Now())
+	return 0
+}
+
+// loadHistory reads the history log. Malformed lines are reported on
+// stderr and skipped.
+func loadHistory() ([]history.Record, error) {
+	path, err := paths.HistoryFile()
+	if err != nil {
+		return nil, err
+	}
+	recs, err := history.NewFileStore(path).Load()
+	var malformed *history.MalformedError
+	if errors.As(err, &malformed) {
+		fmt.Fprintf(os.Stderr, "pomodoro: %v\n", err)
+		err = nil
+	}
+	return recs, err
+}
+
+// printStats writes the report for the periods containing now.
+func printStats(w io.Writer, r stats.Report, now time.Time) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "\tpomodoros\tfocus\tbreaks\tcompleted")
+	for _, p := range []struct {
+		name string
+		t    stats.Totals
+	}{
+		{"today", r.Day(now)},
+		{"this week", r.Week(now)},
+		{"this month", r.Month(now)},
+	} {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%.0f%%\n", p.name, p.t.Pomodoros,
+			p.t.Focus.Round(time.Minute), p.t.Break.Round(time.Minute), 100*p.t.CompletionRate())
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\nlongest streak: %d day(s)\n", r.LongestStreak)
+	if tasks := r.Week(now).Tasks; len(tasks) > 0 {
+		fmt.Fprintln(w, "\ntasks this week:")
+		for _, t := range tasks {
+			fmt.Fprintf(tw, "  %s\t%d\t%s\n", t.Task, t.Pomodoros, t.Focus.Round(time.Minute))
+		}
+		tw.Flush()
+	}
+}
@@ -7,69 +7,173 @@ import (
 	"context"
 	"sync"
 	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/clock"
 )
 
 // State represents the observable lifecycle state of the pomodoro app.
 //
-// Typical values are `StateIdle`, `StatePomodoroRunning` and
-// `StateBreakRunning`.
+// Typical values are `StateIdle`, `StatePomodoroRunning`,
+// `StateBreakRunning` and `StatePaused`.
 type State string
 
 const (
 	StateIdle            State = "Idle"
 	StatePomodoroRunning State = "PomodoroRunning"
 	StateBreakRunning    State = "BreakRunning"
+	StatePaused          State = "Paused"
+)
+
+// Kind identifies the type of session: a pomodoro, a short break or a
+// long break. The zero Kind means no session.
+type Kind string
+
+const (
+	KindPomodoro   Kind = "pomodoro"
+	KindShortBreak Kind = "short-break"
+	KindLongBreak  Kind = "long-break"
 )
 
-// App is the minimal domain API the demo UI uses. It allows starting a
-// pomodoro or break, shutting down the app, subscribing to state changes,
-// and querying the current state and remaining time.
+// runningState returns the state the app is in while a session of kind k
+// is running.
+func runningState(k Kind) State {
+	if k == KindPomodoro {
+		return StatePomodoroRunning
+	}
+	return StateBreakRunning
+}
+
+// App is the minimal domain API the demo UI uses. It allows starting,
+// pausing and resuming a pomodoro or break, shutting down the app,
+// subscribing to state changes, and querying the current state and
+// remaining time.
 type App interface {
 	StartPomodoro()
+	// StartPomodoroFor begins a pomodoro labelled with task; Task
+	// reports the label of the active session.
+	StartPomodoroFor(task string)
 	StartBreak()
 	StartShortBreak()
 	StartLongBreak()
+	// Pause freezes the running session; Resume continues it with the
+	// remaining time. Both are no-ops when not applicable.
+	Pause()
+	Resume()
+	// Extend adds d to the running or paused session without changing
+	// its state. It is a no-op when idle or when d is not positive.
+	Extend(d time.Duration)
+	// CompleteNow ends the session early and counts it as completed;
+	// Skip abandons it without counting. Both are no-ops when idle.
+	CompleteNow()
+	Skip()
 	Shutdown(ctx context.Context) error
 	OnStateChange(fn func(State))
 	// SubscribeStateChange registers a listener for state changes and returns an
 	// unsubscribe function. The unsubscribe function is safe to call multiple
 	// times and may be called from any goroutine.
 	SubscribeStateChange(fn func(State)) func()
+	// SubscribeEvents registers a listener for transition events and returns
+	// an unsubscribe function with the same guarantees as
+	// `SubscribeStateChange`.
+	SubscribeEvents(fn func(Event)) func()
 	State() State
+	// Kind reports the kind of the running or paused session, or the zero
+	// Kind when idle.
+	Kind() Kind
+	Task() string
+	// RecordInterruption counts an interruption of the running pomodoro;
+	// Interruptions reports the counts of the active session.
+	RecordInterruption(k InterruptionKind) error
+	Interruptions() Interruptions
+	// Cycle reports how many pomodoros of the current cycle are done.
+	Cycle() Cycle
 	Remaining() time.Duration
+	// Snapshot captures the timer state; Restore brings an idle app back
+	// to a captured state.
+	Snapshot() Snapshot
+	Restore(s Snapshot) error
+	// UpdateSettings changes durations and cycle configuration at runtime.
+	// An active session keeps its end time; new durations apply from the
+	// next session on.
+	UpdateSettings(s Settings)
 }
 
 type timerApp struct {
-	mu          sync.Mutex
-	state       State
-	cancelTimer context.CancelFunc
-	// subscribers holds active state-change listeners keyed by id.
-	subscribers       map[int]func(State)
+	mu    sync.Mutex
+	state State
+	clock clock.Clock
+	// timer fires when the running session ends; timerSeq identifies the
+	// armed timer so a stale expiry can be ignored.
+	timer    clock.Timer
+	timerSeq int
+	// subscribers holds active event listeners keyed by id.
+	subscribers       map[int]func(Event)
 	nextSubID         int
 	pomodoroDuration  time.Duration
 	breakDuration     time.Duration
 	longBreakDuration time.Duration
 	end               time.Time
+	// kind is the kind of the running or paused session and task its
+	// label; lastTask is the label of the most recent labelled pomodoro.
+	kind     Kind
+	task     string
+	lastTask string
+	// interruptions counts the interruptions of the active pomodoro.
+	interruptions Interruptions
+	// planned is the full duration of the running or paused session and
+	// started is when it began.
+	planned time.Duration
+	started time.Time
+	// paused holds the remaining time of a paused session.
+	paused time.Duration
+	// ran is how long the session ran before the current running
+	// stretch, which began at since.
+	ran   time.Duration
+	since time.Time
+	// completed counts pomodoros finished in the current cycle.
+	completed      int
+	cycleLength    int
+	cycleIdleReset time.Duration
+	// lastEnded is when the app last returned to idle.
+	lastEnded   time.Time
+	autoAdvance AutoAdvance
 }
 
-// New creates a new App instance. Optionally pass two durations: pomodoro, break.
+// New creates a new App instance. It is kept for compatibility with
+// earlier callers; prefer `NewWithOptions`. The optional durations set the
+// pomodoro, short break and long break, in that order. Non-positive
+// durations use the defaults and extra durations are ignored.
 // Examples:
 //
 //	New() // uses defaults
 //	New(10*time.Millisecond, 5*time.Millisecond) // test-friendly durations
 func New(durations ...time.Duration) App {
-	pom := 25 * time.Minute
-	brk := 5 * time.Minute
-	longBrk := 25 * time.Minute
-	if len(durations) >= 2 {
-		pom = durations[0]
-		brk = durations[1]
+	setters := []func(time.Duration) Option{WithPomodoro, WithShortBreak, WithLongBreak}
+	var opts []Option
+	for i, d := range durations {
+		if i < len(setters) && d > 0 {
+			opts = append(opts, setters[i](d))
+		}
+	}
+	a, err := NewWithOptions(opts...)
+	if err != nil {
+		// only valid options are passed above
+		panic(err)
 	}
+	return a
+}
+
+// newTimerApp creates the App implementation from complete settings.
+func newTimerApp(c clock.Clock, s Settings) *timerApp {
 	return &timerApp{
 		state:             StateIdle,
-		pomodoroDuration:  pom,
-		breakDuration:     brk,
-		longBreakDuration: longBrk,
+		clock:             c,
+		pomodoroDuration:  s.Pomodoro,
+		breakDuration:     s.ShortBreak,
+		longBreakDuration: s.LongBreak,
+		cycleLength:       s.CycleLength,
+		cycleIdleReset:    s.CycleIdleReset,
+		autoAdvance:       s.AutoAdvance,
 	}
 }
 
@@ -80,6 +184,22 @@ func (t *timerApp) State() State {
 	return t.state
 }
 
+// Kind returns the kind of the running or paused session, or the zero
+// Kind when idle.
+func (t *timerApp) Kind() Kind {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.kind
+}
+
+// Cycle returns the progress through the current pomodoro cycle.
+func (t *timerApp) Cycle() Cycle {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.resetStaleCycle(t.clock.Now())
+	return Cycle{Completed: t.completed, Length: t.cycleLength}
+}
+
 // OnStateChange registers a callback to be invoked on state transitions.
 // The callback is invoked asynchronously. For an unsubscribe function
 // use `SubscribeStateChange` which returns a cleanup function.
@@ -92,9 +212,20 @@ func (t *timerApp) OnStateChange(fn func(State)) {
 // an unsubscribe function. The unsubscribe function is safe to call
 // multiple times and may be called from any goroutine.
 func (t *timerApp) SubscribeStateChange(fn func(State)) func() {
+	return t.SubscribeEvents(func(e Event) {
+		if e.IsStateChange() {
+			fn(e.To)
+		}
+	})
+}
+
+// SubscribeEvents registers a listener for transition events and returns
+// an unsubscribe function. Events are delivered synchronously on the
+// goroutine that caused the transition, after the app lock is released.
+func (t *timerApp) SubscribeEvents(fn func(Event)) func() {
 	t.mu.Lock()
 	if t.subscribers == nil {
-		t.subscribers = make(map[int]func(State))
+		t.subscribers = make(map[int]func(Event))
 	}
 	id := t.nextSubID
 	t.nextSubID++
@@ -108,43 +239,51 @@ func (t *timerApp) SubscribeStateChange(fn func(State)) func() {
 	}
 }
 
-func (t *timerApp) notifySubscribers(s State) {
+// notifySubscribers delivers events in order to every subscriber. It
+// must be called without holding t.mu.
+func (t *timerApp) notifySubscribers(events ...Event) {
 	t.mu.Lock()
 	// copy subscribers to avoid holding lock while calling callbacks
-	subs := make([]func(State), 0, len(t.subscribers))
+	subs := make([]func(Event), 0, len(t.subscribers))
 	for _, fn := range t.subscribers {
 		subs = append(subs, fn)
 	}
 	t.mu.Unlock()
 
-	for _, fn := range subs {
-		if fn != nil {
-			fn(s)
+	for _, e := range events {
+		for _, fn := range subs {
+			if fn != nil {
+				fn(e)
+			}
 		}
 	}
 }
 
 func (t *timerApp) cancelExistingTimer() {
-	if t.cancelTimer != nil {
-		t.cancelTimer()
-		t.cancelTimer = nil
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
 		t.end = time.Time{}
 	}
 }
 
 // Remaining returns the remaining duration for the current running
-// session (pomodoro or break). It returns zero when no session is
-// active or when the remaining time has elapsed.
+// session (pomodoro or break). While paused it returns the frozen
+// remainder. It returns zero when no session is active or when the
+// remaining time has elapsed.
 func (t *timerApp) Remaining() time.Duration {
 	t.mu.Lock()
 	defer t.mu.Unlock()
+	if t.state == StatePaused {
+		return t.paused
+	}
 	if t.state != StatePomodoroRunning && t.state != StateBreakRunning {
 		return 0
 	}
 	if t.end.IsZero() {
 		return 0
 	}
-	d := time.Until(t.end)
+	d := t.end.Sub(t.clock.Now())
 	if d <= 0 {
 		return 0
 	}
@@ -154,101 +293,297 @@ func (t *timerApp) Remaining() time.Duration {
 // StartPomodoro begins a pomodoro session. If a pomodoro is already
 // running this is a no-op.
 func (t *timerApp) StartPomodoro() {
-	t.mu.Lock()
-	if t.state == StatePomodoroRunning {
-		t.mu.Unlock()
-		return
-	}
-	t.cancelExistingTimer()
-	ctx, cancel := context.WithCancel(context.Background())
-	t.cancelTimer = cancel
-	t.end = time.Now().Add(t.pomodoroDuration)
-	t.state = StatePomodoroRunning
-	t.mu.Unlock()
+	t.start(KindPomodoro, t.pomodoroDuration, "")
+}
 
-	t.notifySubscribers(StatePomodoroRunning)
-
-	go func() {
-		select {
-		case <-time.After(t.pomodoroDuration):
-			t.mu.Lock()
-			t.cancelTimer = nil
-			t.state = StateIdle
-			t.mu.Unlock()
-			t.notifySubscribers(StateIdle)
-		case <-ctx.Done():
-			return
-		}
-	}()
+// StartPomodoroFor begins a pomodoro spent on task. It is a no-op when a
+// pomodoro for the same task is already running; a pomodoro for another
+// task is replaced.
+func (t *timerApp) StartPomodoroFor(task string) {
+	t.start(KindPomodoro, t.pomodoroDuration, task)
+}
+
+// Task returns the label of the running or paused pomodoro.
+func (t *timerApp) Task() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.task
 }
 
-// StartBreak begins a break session. If a break is already running this
-// is a no-op.
+// StartBreak begins the next break of the cycle: a long break once the
+// configured number of pomodoros is completed, a short break otherwise.
+// If a break is already running this is a no-op.
 func (t *timerApp) StartBreak() {
-	// Keep compatibility: StartBreak starts a short break by default.
+	if t.Cycle().Done() {
+		t.StartLongBreak()
+		return
+	}
 	t.StartShortBreak()
 }
 
 // StartShortBreak begins a short break using the configured short break
 // duration. If a short break is already running this is a no-op.
 func (t *timerApp) StartShortBreak() {
+	t.start(KindShortBreak, t.breakDuration, "")
+}
+
+// StartLongBreak begins a long break using the configured long break
+// duration. If a break is already running this is a no-op.
+func (t *timerApp) StartLongBreak() {
+	t.start(KindLongBreak, t.longBreakDuration, "")
+}
+
+// start begins a session of kind k lasting d, labelled with task,
+// replacing any active or paused session. It is a no-op when a session
+// with the same running state is already active, unless task names a
+// different task than the running pomodoro.
+func (t *timerApp) start(k Kind, d time.Duration, task string) {
 	t.mu.Lock()
-	if t.state == StateBreakRunning {
+	if t.state == runningState(k) && (task == "" || task == t.task) {
 		t.mu.Unlock()
 		return
 	}
+	now := t.clock.Now()
+	from := t.state
+	var events []Event
+	if t.kind != "" {
+		events = t.finish(ReasonSuperseded, runningState(k), now)
+	}
+	t.resetStaleCycle(now)
+	started := t.begin(k, d, task, now)
+	// the replaced session never went idle
+	started.From = from
+	events = append(events, started)
+	t.mu.Unlock()
+
+	t.notifySubscribers(events...)
+}
+
+// begin arms a new session of kind k lasting d and returns its started
+// event. It must be called with t.mu held.
+func (t *timerApp) begin(k Kind, d time.Duration, task string, now time.Time) Event {
+	from := t.state
 	t.cancelExistingTimer()
-	ctx, cancel := context.WithCancel(context.Background())
-	t.cancelTimer = cancel
-	t.end = time.Now().Add(t.breakDuration)
-	t.state = StateBreakRunning
+	t.kind = k
+	t.task = task
+	t.interruptions = Interruptions{}
+	if task != "" {
+		t.lastTask = task
+	}
+	t.planned = d
+	t.started = now
+	t.paused = 0
+	t.ran = 0
+	t.since = now
+	t.arm(d)
+	e := t.event(t.state, ReasonStarted, now)
+	e.From = from
+	e.End = t.end
+	return e
+}
+
+// event describes a transition of the current session from the current
+// state to the given one. It must be called with t.mu held.
+func (t *timerApp) event(to State, r Reason, at time.Time) Event {
+	return Event{
+		From:          t.state,
+		To:            to,
+		Kind:          t.kind,
+		Reason:        r,
+		At:            at,
+		Planned:       t.planned,
+		Started:       t.started,
+		Elapsed:       t.elapsed(at),
+		Task:          t.task,
+		Interruptions: t.interruptions,
+	}
+}
+
+// elapsed returns how long the current session has run at now, not
+// counting pauses. It must be called with t.mu held.
+func (t *timerApp) elapsed(now time.Time) time.Duration {
+	d := t.ran
+	if t.state == StatePomodoroRunning || t.state == StateBreakRunning {
+		if stretch := now.Sub(t.since); stretch > 0 {
+			d += stretch
+		}
+	}
+	return d
+}
+
+// arm moves the app into the running state for the current session kind
+// and schedules the return to idle once d has elapsed. It must be called
+// with t.mu held.
+func (t *timerApp) arm(d time.Duration) {
+	t.end = t.clock.Now().Add(d)
+	t.state = runningState(t.kind)
+	t.timerSeq++
+	seq := t.timerSeq
+	t.timer = t.clock.AfterFunc(d, func() { t.expire(seq) })
+}
+
+// expire ends the session armed as seq and returns the app to idle.
+func (t *timerApp) expire(seq int) {
+	t.mu.Lock()
+	// the session may have been cancelled while the timer fired
+	if t.timer == nil || seq != t.timerSeq {
+		t.mu.Unlock()
+		return
+	}
+	events := t.finish(ReasonCompleted, StateIdle, t.clock.Now())
 	t.mu.Unlock()
+	t.notifySubscribers(events...)
+}
+
+// CompleteNow ends the running or paused session as if it had run for
+// its full duration: a pomodoro counts towards the cycle and the
+// auto-advance policy applies. It is a no-op when idle.
+func (t *timerApp) CompleteNow() {
+	t.stop(ReasonCompleted)
+}
+
+// Skip abandons the running or paused session without counting it. It
+// is a no-op when idle.
+func (t *timerApp) Skip() {
+	t.stop(ReasonCancelled)
+}
 
-	t.notifySubscribers(StateBreakRunning)
-
-	go func() {
-		select {
-		case <-time.After(t.breakDuration):
-			t.mu.Lock()
-			t.cancelTimer = nil
-			t.state = StateIdle
-			t.mu.Unlock()
-			t.notifySubscribers(StateIdle)
-		case <-ctx.Done():
-			return
+// stop finishes the active session for reason r, if any.
+func (t *timerApp) stop(r Reason) {
+	t.mu.Lock()
+	if t.state == StateIdle {
+		t.mu.Unlock()
+		return
+	}
+	events := t.finish(r, StateIdle, t.clock.Now())
+	t.mu.Unlock()
+	t.notifySubscribers(events...)
+}
+
+// finish returns the app to idle, ending the current session for reason
+// r in a transition to state to: idle, or the running state of the
+// session replacing it. The cycle is updated however the session ends,
+// and a completed session may start the next one. It must be called with
+// t.mu held and returns the events to deliver.
+func (t *timerApp) finish(r Reason, to State, now time.Time) []Event {
+	events := []Event{t.event(to, r, now)}
+	t.cancelExistingTimer()
+	done := t.kind
+	t.endSession(r, now)
+	t.clearSession()
+	if r != ReasonCompleted {
+		return events
+	}
+	if k, d, ok := t.next(done); ok {
+		var task string
+		if k == KindPomodoro {
+			// keep working on the same task
+			task = t.lastTask
 		}
-	}()
+		events = append(events, t.begin(k, d, task, now))
+	}
+	return events
 }
 
-// StartLongBreak begins a long break using the configured long break
-// duration. If a break is already running this is a no-op.
-func (t *timerApp) StartLongBreak() {
+// clearSession forgets the current session and returns the app to idle.
+// It must be called with t.mu held.
+func (t *timerApp) clearSession() {
+	t.kind = ""
+	t.task = ""
+	t.interruptions = Interruptions{}
+	t.planned = 0
+	t.started = time.Time{}
+	t.paused = 0
+	t.ran = 0
+	t.since = time.Time{}
+	t.state = StateIdle
+}
+
+// next returns the session the auto-advance policy starts after a session
+// of kind done completed. It must be called with t.mu held.
+func (t *timerApp) next(done Kind) (Kind, time.Duration, bool) {
+	switch {
+	case done == KindPomodoro && t.autoAdvance >= AutoAdvanceBreaks:
+		if t.completed >= t.cycleLength {
+			return KindLongBreak, t.longBreakDuration, true
+		}
+		return KindShortBreak, t.breakDuration, true
+	case done != KindPomodoro && t.autoAdvance >= AutoAdvanceAll:
+		return KindPomodoro, t.pomodoroDuration, true
+	}
+	return "", 0, false
+}
+
+// Pause freezes the running session, keeping its kind and remaining
+// time. It is a no-op unless a pomodoro or break is running.
+func (t *timerApp) Pause() {
 	t.mu.Lock()
-	if t.state == StateBreakRunning {
+	if t.state != StatePomodoroRunning && t.state != StateBreakRunning {
 		t.mu.Unlock()
 		return
 	}
+	now := t.clock.Now()
+	rem := t.end.Sub(now)
+	if rem < 0 {
+		rem = 0
+	}
+	e := t.event(StatePaused, ReasonPaused, now)
 	t.cancelExistingTimer()
-	ctx, cancel := context.WithCancel(context.Background())
-	t.cancelTimer = cancel
-	t.end = time.Now().Add(t.longBreakDuration)
-	t.state = StateBreakRunning
+	t.ran = e.Elapsed
+	t.paused = rem
+	t.state = StatePaused
+	t.mu.Unlock()
+
+	t.notifySubscribers(e)
+}
+
+// Resume continues a paused session with the time that was left when it
+// was paused. It is a no-op unless the app is paused.
+func (t *timerApp) Resume() {
+	t.mu.Lock()
+	if t.state != StatePaused {
+		t.mu.Unlock()
+		return
+	}
+	now := t.clock.Now()
+	e := t.event(runningState(t.kind), ReasonResumed, now)
+	t.since = now
+	t.arm(t.paused)
+	t.paused = 0
+	e.End = t.end
 	t.mu.Unlock()
 
-	t.notifySubscribers(StateBreakRunning)
-
-	go func() {
-		select {
-		case <-time.After(t.longBreakDuration):
-			t.mu.Lock()
-			t.cancelTimer = nil
-			t.state = StateIdle
-			t.mu.Unlock()
-			t.notifySubscribers(StateIdle)
-		case <-ctx.Done():
-			return
+	t.notifySubscribers(e)
+}
+
+// Extend pushes the end of the running session out by d, or adds d to
+// the remainder of a paused one. The session keeps its state, so only an
+// extended event is emitted.
+func (t *timerApp) Extend(d time.Duration) {
+	t.mu.Lock()
+	if d <= 0 || t.state == StateIdle {
+		t.mu.Unlock()
+		return
+	}
+	now := t.clock.Now()
+	t.planned += d
+	if t.state == StatePaused {
+		t.paused += d
+	} else {
+		rem := t.end.Sub(now)
+		if rem < 0 {
+			rem = 0
 		}
-	}()
+		t.cancelExistingTimer()
+		t.arm(rem + d)
+	}
+	e := t.event(t.state, ReasonExtended, now)
+	if t.state != StatePaused {
+		e.End = t.end
+	}
+	t.mu.Unlock()
+
+	t.notifySubscribers(e)
 }
 
 // Shutdown stops any active session, transitions the app to idle, and
@@ -257,9 +592,14 @@ func (t *timerApp) StartLongBreak() {
 // implementation).
 func (t *timerApp) Shutdown(ctx context.Context) error {
 	t.mu.Lock()
+	now := t.clock.Now()
+	e := t.event(StateIdle, ReasonShutdown, now)
 	t.cancelExistingTimer()
-	t.state = StateIdle
+	if t.state != StateIdle {
+		t.lastEnded = now
+	}
+	t.clearSession()
 	t.mu.Unlock()
-	t.notifySubscribers(StateIdle)
+	t.notifySubscribers(e)
 	return nil
 }
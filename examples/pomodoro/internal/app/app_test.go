@@ -4,32 +4,43 @@ import (
 	"context"
 	"testing"
 	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/clock"
 )
 
+var epoch = time.Date(2025, 12, 5, 9, 0, 0, 0, time.UTC)
+
+// newFakeApp returns an app with default settings driven by a fake clock.
+func newFakeApp(t *testing.T) (*timerApp, *clock.Fake) {
+	t.Helper()
+	c := clock.NewFake(epoch)
+	return newTimerApp(c, DefaultSettings()), c
+}
+
 func TestPomodoroTransitionAndIdle(t *testing.T) {
-	// very short durations to keep tests fast
-	a := New(10*time.Millisecond, 5*time.Millisecond)
+	a, c := newFakeApp(t)
 
 	stateCh := make(chan State, 4)
 	a.OnStateChange(func(s State) { stateCh <- s })
 
 	a.StartPomodoro()
+	if got := <-stateCh; got != StatePomodoroRunning {
+		t.Fatalf("expected PomodoroRunning, got %s", got)
+	}
+
+	c.Advance(DefaultPomodoro - time.Second)
+	if a.State() != StatePomodoroRunning {
+		t.Fatalf("expected pomodoro still running, got %s", a.State())
+	}
 
-	// expect PomodoroRunning then Idle shortly after
-	var seenPomodoro, seenIdle bool
-	timeout := time.After(200 * time.Millisecond)
-	for !seenIdle {
-		select {
-		case s := <-stateCh:
-			if s == StatePomodoroRunning {
-				seenPomodoro = true
-			}
-			if s == StateIdle && seenPomodoro {
-				seenIdle = true
-			}
-		case <-timeout:
-			t.Fatalf("timeout waiting for state transitions: seenPomodoro=%v", seenPomodoro)
+	c.Advance(time.Second)
+	select {
+	case got := <-stateCh:
+		if got != StateIdle {
+			t.Fatalf("expected Idle, got %s", got)
 		}
+	default:
+		t.Fatal("expected Idle once the pomodoro elapsed")
 	}
 }
 
@@ -46,7 +57,7 @@ func TestIdempotentStarts(t *testing.T) {
 }
 
 func TestShutdownCancelsTimer(t *testing.T) {
-	a := New(1*time.Second, 1*time.Second)
+	a, c := newFakeApp(t)
 	a.StartPomodoro()
 
 	// ensure we're in running state
@@ -62,36 +73,67 @@ func TestShutdownCancelsTimer(t *testing.T) {
 	if a.State() != StateIdle {
 		t.Fatalf("expected idle after shutdown, got %s", a.State())
 	}
+	if c.Pending() != 0 {
+		t.Fatalf("expected timer to be cancelled, %d pending", c.Pending())
+	}
+
+	// the cancelled pomodoro must not count towards the cycle
+	c.Advance(time.Hour)
+	if got := a.Cycle().Completed; got != 0 {
+		t.Fatalf("expected no completed pomodoros, got %d", got)
+	}
 }
 
 func TestStartShortAndLongBreaks(t *testing.T) {
-	// use short durations for fast tests
-	a := New(25*time.Millisecond, 5*time.Millisecond)
-	// adjust long break duration directly for test
-	if ta, ok := a.(*timerApp); ok {
-		ta.longBreakDuration = 50 * time.Millisecond
-	}
+	a, c := newFakeApp(t)
 
 	// Short break
 	a.StartShortBreak()
 	if a.State() != StateBreakRunning {
 		t.Fatalf("expected short break running, got %s", a.State())
 	}
-	rem := a.Remaining()
-	if rem <= 0 || rem > 5*time.Millisecond+20*time.Millisecond {
+	if rem := a.Remaining(); rem != DefaultShortBreak {
 		t.Fatalf("unexpected remaining for short break: %v", rem)
 	}
 
 	// Wait for short break to finish
-	time.Sleep(30 * time.Millisecond)
+	c.Advance(DefaultShortBreak)
+	if a.State() != StateIdle {
+		t.Fatalf("expected idle after short break, got %s", a.State())
+	}
 
 	// Long break
 	a.StartLongBreak()
 	if a.State() != StateBreakRunning {
 		t.Fatalf("expected long break running, got %s", a.State())
 	}
-	rem = a.Remaining()
-	if rem <= 0 || rem > 50*time.Millisecond+20*time.Millisecond {
+	c.Advance(time.Minute)
+	if rem := a.Remaining(); rem != DefaultLongBreak-time.Minute {
 		t.Fatalf("unexpected remaining for long break: %v", rem)
 	}
 }
+
+func TestFullCycleWithFakeClock(t *testing.T) {
+	a, c := newFakeApp(t)
+
+	var transitions []State
+	a.OnStateChange(func(s State) { transitions = append(transitions, s) })
+
+	for i := 0; i < DefaultCycleLength; i++ {
+		a.StartPomodoro()
+		c.Advance(DefaultPomodoro)
+		a.StartBreak()
+		c.Advance(DefaultLongBreak)
+	}
+
+	// three short breaks, then a long break that closes the cycle
+	if got := a.Cycle().String(); got != "0/4" {
+		t.Fatalf("expected a fresh cycle, got %s", got)
+	}
+	if want := 4 * DefaultCycleLength; len(transitions) != want {
+		t.Fatalf("expected %d transitions, got %d: %v", want, len(transitions), transitions)
+	}
+	if elapsed := c.Now().Sub(epoch); elapsed != 4*(DefaultPomodoro+DefaultLongBreak) {
+		t.Fatalf("unexpected elapsed fake time %v", elapsed)
+	}
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"fmt"
+	"time"
+)
+
+// Cycle reports progress through the current pomodoro cycle: how many
+// pomodoros were completed out of the configured cycle length.
+type Cycle struct {
+	Completed int
+	Length    int
+}
+
+// String formats the cycle progress, for example "3/4".
+func (c Cycle) String() string {
+	return fmt.Sprintf("%d/%d", c.Completed, c.Length)
+}
+
+// Done reports whether the cycle is complete and the next break should be
+// a long one.
+func (c Cycle) Done() bool {
+	return c.Length > 0 && c.Completed >= c.Length
+}
+
+// resetStaleCycle clears the cycle count when the app has been idle for
+// longer than the configured idle gap. It must be called with t.mu held.
+func (t *timerApp) resetStaleCycle(now time.Time) {
+	if t.state != StateIdle || t.cycleIdleReset < 0 || t.lastEnded.IsZero() {
+		return
+	}
+	if now.Sub(t.lastEnded) >= t.cycleIdleReset {
+		t.completed = 0
+	}
+}
+
+// endSession records the end of the current session for reason r in the
+// cycle: a completed pomodoro counts towards the cycle and a long break
+// closes it however it ends. It must be called with t.mu held.
+func (t *timerApp) endSession(r Reason, now time.Time) {
+	switch {
+	case t.kind == KindPomodoro && r == ReasonCompleted:
+		t.completed++
+	case t.kind == KindLongBreak:
+		t.completed = 0
+	}
+	t.lastEnded = now
+}
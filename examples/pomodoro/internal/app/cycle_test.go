@@ -0,0 +1,110 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/clock"
+)
+
+func TestCycleCountsCompletedPomodoros(t *testing.T) {
+	c := clock.NewFake(epoch)
+	a := newTimerApp(c, Settings{CycleLength: 3}.withDefaults())
+
+	if got := a.Cycle().String(); got != "0/3" {
+		t.Fatalf("expected 0/3, got %s", got)
+	}
+
+	for i := 1; i <= 2; i++ {
+		a.StartPomodoro()
+		c.Advance(DefaultPomodoro)
+		a.StartBreak()
+		if a.Kind() != KindShortBreak {
+			t.Fatalf("pomodoro %d: expected short break, got %q", i, a.Kind())
+		}
+	}
+	if got := a.Cycle().String(); got != "2/3" {
+		t.Fatalf("expected 2/3, got %s", got)
+	}
+
+	a.StartPomodoro()
+	c.Advance(DefaultPomodoro)
+	if got := a.Cycle().String(); got != "3/3" {
+		t.Fatalf("expected 3/3, got %s", got)
+	}
+	a.StartBreak()
+	if a.Kind() != KindLongBreak {
+		t.Fatalf("expected long break after full cycle, got %q", a.Kind())
+	}
+
+	// cutting the long break short with a new pomodoro closes the cycle
+	a.StartPomodoro()
+	if got := a.Cycle().String(); got != "0/3" {
+		t.Fatalf("expected cycle reset after long break, got %s", got)
+	}
+}
+
+func TestCycleResetsAfterLongBreakCompletes(t *testing.T) {
+	c := clock.NewFake(epoch)
+	a := newTimerApp(c, Settings{CycleLength: 1}.withDefaults())
+
+	a.StartPomodoro()
+	c.Advance(DefaultPomodoro)
+	a.StartBreak()
+	if a.Kind() != KindLongBreak {
+		t.Fatalf("expected long break, got %q", a.Kind())
+	}
+	c.Advance(DefaultLongBreak)
+	if got := a.Cycle().String(); got != "0/1" {
+		t.Fatalf("expected 0/1 after long break, got %s", got)
+	}
+}
+
+func TestSkippingLongBreakClosesCycle(t *testing.T) {
+	c := clock.NewFake(epoch)
+	a := newTimerApp(c, Settings{CycleLength: 2}.withDefaults())
+
+	for round := 1; round <= 2; round++ {
+		for i := 0; i < 2; i++ {
+			a.StartPomodoro()
+			c.Advance(DefaultPomodoro)
+		}
+		a.StartBreak()
+		if a.Kind() != KindLongBreak {
+			t.Fatalf("round %d: expected long break, got %q", round, a.Kind())
+		}
+		a.Skip()
+		if got := a.Cycle().String(); got != "0/2" {
+			t.Fatalf("round %d: expected 0/2 after skipping the long break, got %s", round, got)
+		}
+	}
+
+	// the first break of the new cycle is a short one again
+	a.StartPomodoro()
+	c.Advance(DefaultPomodoro)
+	a.StartBreak()
+	if a.Kind() != KindShortBreak {
+		t.Fatalf("expected short break, got %q", a.Kind())
+	}
+}
+
+func TestCycleResetsAfterIdleGap(t *testing.T) {
+	c := clock.NewFake(epoch)
+	a := newTimerApp(c, Settings{CycleIdleReset: 30 * time.Minute}.withDefaults())
+
+	a.StartPomodoro()
+	c.Advance(DefaultPomodoro)
+	if got := a.Cycle().Completed; got != 1 {
+		t.Fatalf("expected 1 completed, got %d", got)
+	}
+
+	c.Advance(29 * time.Minute)
+	if got := a.Cycle().Completed; got != 1 {
+		t.Fatalf("expected cycle kept within idle gap, got %d", got)
+	}
+
+	c.Advance(time.Minute)
+	if got := a.Cycle().String(); got != "0/4" {
+		t.Fatalf("expected cycle reset after idle gap, got %s", got)
+	}
+}
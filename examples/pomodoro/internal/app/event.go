@@ -0,0 +1,87 @@
+package app
+
+import "time"
+
+// Reason explains why a transition happened.
+type Reason string
+
+const (
+	// ReasonStarted: a new session began.
+	ReasonStarted Reason = "started"
+	// ReasonCompleted: the session ran for its full duration.
+	ReasonCompleted Reason = "completed"
+	// ReasonCancelled: the session was abandoned before its end.
+	ReasonCancelled Reason = "cancelled"
+	// ReasonShutdown: the app shut down, ending any active session.
+	ReasonShutdown Reason = "shutdown"
+	// ReasonSuperseded: the session was replaced by a newly started one.
+	ReasonSuperseded Reason = "superseded"
+	// ReasonPaused and ReasonResumed: the session was paused or resumed.
+	ReasonPaused  Reason = "paused"
+	ReasonResumed Reason = "resumed"
+	// ReasonRestored: a session was brought back from a snapshot.
+	ReasonRestored Reason = "restored"
+	// ReasonExpired: a restored session had ended long before the app
+	// came back and does not count as completed.
+	ReasonExpired Reason = "expired"
+	// ReasonExtended: time was added to the active session. The state
+	// does not change.
+	ReasonExtended Reason = "extended"
+	// ReasonInterrupted: an interruption was recorded on the running
+	// pomodoro. The state does not change.
+	ReasonInterrupted Reason = "interrupted"
+)
+
+// Event describes a single transition of the timer.
+//
+// Kind, Planned and Started describe the session the event is about: the
+// new session for `ReasonStarted`, the ending one for `ReasonCompleted`,
+// `ReasonSuperseded` and `ReasonShutdown`. They are zero when no session
+// was involved, for example when shutting down while idle.
+type Event struct {
+	From   State
+	To     State
+	Kind   Kind
+	Reason Reason
+	// At is when the transition happened, according to the app clock.
+	At time.Time
+	// Planned is the full configured duration of the session.
+	Planned time.Duration
+	// Started is when the session began.
+	Started time.Time
+	// Elapsed is how long the session has run so far. Pauses and time
+	// the app was not running do not count.
+	Elapsed time.Duration
+	// End is when the session is due to end, set for started, resumed
+	// and extended events of a running session.
+	End time.Time
+	// Task is the label of the session, if any.
+	Task string
+	// Interruptions counts the interruptions recorded on the session so
+	// far; on the event that ends it they are the final counts.
+	Interruptions Interruptions
+}
+
+// IsStateChange reports whether e is delivered to plain State
+// subscribers. A superseded session is followed by the started event of
+// its replacement, which carries the state change, and an extended or
+// interrupted session stays in its state.
+func (e Event) IsStateChange() bool {
+	switch e.Reason {
+	case ReasonSuperseded, ReasonExtended, ReasonInterrupted:
+		return false
+	}
+	return true
+}
+
+// Ends reports whether e marks the end of a session.
+func (e Event) Ends() bool {
+	if e.Kind == "" {
+		return false
+	}
+	switch e.Reason {
+	case ReasonCompleted, ReasonCancelled, ReasonSuperseded, ReasonShutdown, ReasonExpired:
+		return true
+	}
+	return false
+}
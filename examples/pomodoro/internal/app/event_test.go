@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEventsDescribeTransitions(t *testing.T) {
+	a, c := newFakeApp(t)
+
+	var events []Event
+	a.SubscribeEvents(func(e Event) { events = append(events, e) })
+
+	a.StartPomodoro()
+	c.Advance(10 * time.Minute)
+	a.Pause()
+	a.Resume()
+	c.Advance(15 * time.Minute)
+	a.StartLongBreak()
+	_ = a.Shutdown(context.Background())
+
+	want := []Event{
+		{From: StateIdle, To: StatePomodoroRunning, Kind: KindPomodoro, Reason: ReasonStarted, At: epoch, Planned: DefaultPomodoro, Started: epoch, End: epoch.Add(DefaultPomodoro)},
+		{From: StatePomodoroRunning, To: StatePaused, Kind: KindPomodoro, Reason: ReasonPaused, At: epoch.Add(10 * time.Minute), Planned: DefaultPomodoro, Started: epoch, Elapsed: 10 * time.Minute},
+		{From: StatePaused, To: StatePomodoroRunning, Kind: KindPomodoro, Reason: ReasonResumed, At: epoch.Add(10 * time.Minute), Planned: DefaultPomodoro, Started: epoch, Elapsed: 10 * time.Minute, End: epoch.Add(DefaultPomodoro)},
+		{From: StatePomodoroRunning, To: StateIdle, Kind: KindPomodoro, Reason: ReasonCompleted, At: epoch.Add(25 * time.Minute), Planned: DefaultPomodoro, Started: epoch, Elapsed: DefaultPomodoro},
+		{From: StateIdle, To: StateBreakRunning, Kind: KindLongBreak, Reason: ReasonStarted, At: epoch.Add(25 * time.Minute), Planned: DefaultLongBreak, Started: epoch.Add(25 * time.Minute), End: epoch.Add(25*time.Minute + DefaultLongBreak)},
+		{From: StateBreakRunning, To: StateIdle, Kind: KindLongBreak, Reason: ReasonShutdown, At: epoch.Add(25 * time.Minute), Planned: DefaultLongBreak, Started: epoch.Add(25 * time.Minute)},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %d: %+v", len(want), len(events), events)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Fatalf("event %d:\n got %+v\nwant %+v", i, events[i], want[i])
+		}
+	}
+}
+
+func TestElapsedExcludesPauses(t *testing.T) {
+	a, c := newFakeApp(t)
+	var last Event
+	a.SubscribeEvents(func(e Event) { last = e })
+
+	a.StartPomodoro()
+	c.Advance(10 * time.Minute)
+	a.Pause()
+	c.Advance(time.Hour) // lunch
+	a.Resume()
+	c.Advance(5 * time.Minute)
+	if got := a.Snapshot().Ran; got != 15*time.Minute {
+		t.Fatalf("snapshot ran %s, want 15m", got)
+	}
+	a.Skip()
+	if last.Reason != ReasonCancelled || last.Elapsed != 15*time.Minute {
+		t.Fatalf("expected the cancelled pomodoro to have run 15m, got %+v", last)
+	}
+}
+
+func TestSupersededSessionIsNotAStateChange(t *testing.T) {
+	a, _ := newFakeApp(t)
+
+	var events []Event
+	var states []State
+	a.SubscribeEvents(func(e Event) { events = append(events, e) })
+	a.SubscribeStateChange(func(s State) { states = append(states, s) })
+
+	a.StartPomodoro()
+	a.StartShortBreak()
+
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %+v", events)
+	}
+	sup, started := events[1], events[2]
+	if sup.Reason != ReasonSuperseded || sup.Kind != KindPomodoro || sup.To != StateBreakRunning {
+		t.Fatalf("unexpected superseded event %+v", sup)
+	}
+	if started.Reason != ReasonStarted || started.Kind != KindShortBreak || started.From != StatePomodoroRunning {
+		t.Fatalf("unexpected started event %+v", started)
+	}
+
+	// State subscribers see one notification per transition only.
+	if len(states) != 2 || states[0] != StatePomodoroRunning || states[1] != StateBreakRunning {
+		t.Fatalf("unexpected state notifications %v", states)
+	}
+}
+
+func TestShutdownWhileIdleHasNoSession(t *testing.T) {
+	a, _ := newFakeApp(t)
+
+	var got Event
+	a.SubscribeEvents(func(e Event) { got = e })
+	_ = a.Shutdown(context.Background())
+
+	if got.Reason != ReasonShutdown || got.From != StateIdle || got.Kind != "" || got.Planned != 0 {
+		t.Fatalf("unexpected shutdown event %+v", got)
+	}
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExtendRunningSession(t *testing.T) {
+	a, c := newFakeApp(t)
+
+	var events []Event
+	a.SubscribeEvents(func(e Event) { events = append(events, e) })
+	var states []State
+	a.SubscribeStateChange(func(s State) { states = append(states, s) })
+
+	a.StartPomodoro()
+	c.Advance(24 * time.Minute)
+	a.Extend(5 * time.Minute)
+
+	if rem := a.Remaining(); rem != 6*time.Minute {
+		t.Fatalf("expected 6m left after extending, got %v", rem)
+	}
+	last := events[len(events)-1]
+	if last.Reason != ReasonExtended || last.From != StatePomodoroRunning || last.To != StatePomodoroRunning {
+		t.Fatalf("unexpected extended event %+v", last)
+	}
+	if want := epoch.Add(30 * time.Minute); !last.End.Equal(want) {
+		t.Fatalf("expected new end %v, got %v", want, last.End)
+	}
+	if last.Planned != 30*time.Minute {
+		t.Fatalf("expected planned 30m, got %v", last.Planned)
+	}
+	if len(states) != 1 {
+		t.Fatalf("expected no state change from extending, got %v", states)
+	}
+
+	// the original timer no longer fires
+	c.Advance(time.Minute)
+	if a.State() != StatePomodoroRunning {
+		t.Fatalf("expected pomodoro still running at the old end, got %s", a.State())
+	}
+	c.Advance(5 * time.Minute)
+	if a.State() != StateIdle {
+		t.Fatalf("expected idle at the new end, got %s", a.State())
+	}
+	if c.Pending() != 0 {
+		t.Fatalf("expected no pending timers, got %d", c.Pending())
+	}
+}
+
+func TestExtendPausedAndIdle(t *testing.T) {
+	a, c := newFakeApp(t)
+
+	a.Extend(5 * time.Minute)
+	if a.State() != StateIdle || c.Pending() != 0 {
+		t.Fatal("expected extending while idle to be a no-op")
+	}
+
+	a.StartShortBreak()
+	c.Advance(time.Minute)
+	a.Pause()
+	a.Extend(2 * time.Minute)
+	a.Extend(-time.Minute)
+	if rem := a.Remaining(); rem != 6*time.Minute {
+		t.Fatalf("expected 6m frozen after extending a paused break, got %v", rem)
+	}
+	if a.State() != StatePaused {
+		t.Fatalf("expected still paused, got %s", a.State())
+	}
+}
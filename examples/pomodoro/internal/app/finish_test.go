@@ -0,0 +1,87 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompleteNowCountsPomodoro(t *testing.T) {
+	a, c := newFakeApp(t)
+
+	var events []Event
+	a.SubscribeEvents(func(e Event) { events = append(events, e) })
+
+	a.StartPomodoro()
+	c.Advance(20 * time.Minute)
+	a.CompleteNow()
+
+	if a.State() != StateIdle {
+		t.Fatalf("expected idle, got %s", a.State())
+	}
+	if got := a.Cycle().Completed; got != 1 {
+		t.Fatalf("expected the pomodoro to count, got %d", got)
+	}
+	last := events[len(events)-1]
+	if last.Reason != ReasonCompleted || last.Kind != KindPomodoro || !last.At.Equal(epoch.Add(20*time.Minute)) {
+		t.Fatalf("unexpected completed event %+v", last)
+	}
+	if c.Pending() != 0 {
+		t.Fatalf("expected the timer to be cancelled, got %d pending", c.Pending())
+	}
+
+	// completing a long break closes the cycle, even when paused
+	a.StartLongBreak()
+	a.Pause()
+	a.CompleteNow()
+	if got := a.Cycle().Completed; got != 0 {
+		t.Fatalf("expected the cycle to reset after a long break, got %d", got)
+	}
+}
+
+func TestSkipDoesNotCount(t *testing.T) {
+	a, c := newFakeApp(t)
+
+	var events []Event
+	a.SubscribeEvents(func(e Event) { events = append(events, e) })
+
+	a.StartPomodoro()
+	c.Advance(5 * time.Minute)
+	a.Skip()
+
+	if a.State() != StateIdle {
+		t.Fatalf("expected idle, got %s", a.State())
+	}
+	if got := a.Cycle().Completed; got != 0 {
+		t.Fatalf("expected a skipped pomodoro not to count, got %d", got)
+	}
+	last := events[len(events)-1]
+	if last.Reason != ReasonCancelled || !last.Ends() {
+		t.Fatalf("unexpected cancelled event %+v", last)
+	}
+
+	// no-ops when idle
+	n := len(events)
+	a.Skip()
+	a.CompleteNow()
+	if len(events) != n {
+		t.Fatalf("expected no events while idle, got %+v", events[n:])
+	}
+}
+
+func TestCompleteNowAutoAdvances(t *testing.T) {
+	a, c := newFakeApp(t)
+	a.UpdateSettings(Settings{AutoAdvance: AutoAdvanceBreaks})
+
+	a.StartPomodoro()
+	a.CompleteNow()
+	if a.Kind() != KindShortBreak {
+		t.Fatalf("expected the short break to start, got %q", a.Kind())
+	}
+
+	// skipping never advances
+	a.StartPomodoro()
+	a.Skip()
+	if a.State() != StateIdle || c.Pending() != 0 {
+		t.Fatalf("expected idle after skipping, got %s", a.State())
+	}
+}
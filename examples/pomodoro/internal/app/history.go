@@ -0,0 +1,42 @@
+package app
+
+import (
+	"log"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/history"
+)
+
+// RecordHistory appends a `history.Record` to store whenever a session of
+// a ends, whatever the reason. Append failures are logged and do not
+// affect the timer. The returned function stops recording.
+func RecordHistory(a App, store history.Store) func() {
+	return recordHistory(a, store, log.Default())
+}
+
+// recordHistory is `RecordHistory` with append failures logged to l, or
+// dropped when l is nil.
+func recordHistory(a App, store history.Store, l *log.Logger) func() {
+	return a.SubscribeEvents(func(e Event) {
+		if !e.Ends() {
+			return
+		}
+		if err := store.Append(historyRecord(e)); err != nil && l != nil {
+			l.Printf("history: append failed: %v", err)
+		}
+	})
+}
+
+// historyRecord converts a session-ending event into a history record.
+func historyRecord(e Event) history.Record {
+	return history.Record{
+		Kind:                  string(e.Kind),
+		Start:                 e.Started,
+		End:                   e.At,
+		Planned:               e.Planned,
+		Elapsed:               e.Elapsed,
+		Outcome:               history.Outcome(e.Reason),
+		Label:                 e.Task,
+		InternalInterruptions: e.Interruptions.Internal,
+		ExternalInterruptions: e.Interruptions.External,
+	}
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/history"
+)
+
+func TestRecordHistoryAppendsEndedSessions(t *testing.T) {
+	a, c := newFakeApp(t)
+	store := history.NewMemoryStore()
+	stop := RecordHistory(a, store)
+
+	a.StartPomodoro()
+	c.Advance(DefaultPomodoro)
+	a.StartShortBreak()
+	c.Advance(time.Minute)
+	a.StartPomodoro() // supersedes the break
+	c.Advance(5 * time.Minute)
+	a.Pause()
+	c.Advance(10 * time.Minute)
+	_ = a.Shutdown(context.Background())
+
+	recs, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []history.Record{
+		{Kind: "pomodoro", Start: epoch, End: epoch.Add(25 * time.Minute), Planned: DefaultPomodoro, Elapsed: DefaultPomodoro, Outcome: history.OutcomeCompleted},
+		{Kind: "short-break", Start: epoch.Add(25 * time.Minute), End: epoch.Add(26 * time.Minute), Planned: DefaultShortBreak, Elapsed: time.Minute, Outcome: history.OutcomeSuperseded},
+		// the pause does not count as run time
+		{Kind: "pomodoro", Start: epoch.Add(26 * time.Minute), End: epoch.Add(41 * time.Minute), Planned: DefaultPomodoro, Elapsed: 5 * time.Minute, Outcome: history.OutcomeShutdown},
+	}
+	if len(recs) != len(want) {
+		t.Fatalf("expected %d records, got %+v", len(want), recs)
+	}
+	for i := range want {
+		if recs[i] != want[i] {
+			t.Fatalf("record %d:\n got %+v\nwant %+v", i, recs[i], want[i])
+		}
+	}
+
+	// nothing is recorded once stopped, nor for an idle shutdown
+	stop()
+	a.StartPomodoro()
+	c.Advance(DefaultPomodoro)
+	if recs, _ := store.Load(); len(recs) != len(want) {
+		t.Fatalf("expected no records after stop, got %d", len(recs))
+	}
+}
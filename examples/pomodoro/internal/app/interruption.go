@@ -0,0 +1,67 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+)
+
+// InterruptionKind distinguishes interruptions as the Pomodoro Technique
+// does: internal ones come from yourself, external ones from others.
+type InterruptionKind string
+
+const (
+	InterruptionInternal InterruptionKind = "internal"
+	InterruptionExternal InterruptionKind = "external"
+)
+
+// ErrNotRunning is returned when an action needs a running pomodoro.
+var ErrNotRunning = errors.New("app: no pomodoro running")
+
+// Interruptions counts the interruptions of a pomodoro by kind.
+type Interruptions struct {
+	Internal int
+	External int
+}
+
+// Total returns the number of interruptions of either kind.
+func (i Interruptions) Total() int {
+	return i.Internal + i.External
+}
+
+// String formats the counts, for example "1 internal, 2 external".
+func (i Interruptions) String() string {
+	return fmt.Sprintf("%d internal, %d external", i.Internal, i.External)
+}
+
+// RecordInterruption counts an interruption of kind k on the running
+// pomodoro and emits an interrupted event. It returns `ErrNotRunning`
+// unless a pomodoro is running.
+func (t *timerApp) RecordInterruption(k InterruptionKind) error {
+	t.mu.Lock()
+	if t.state != StatePomodoroRunning {
+		t.mu.Unlock()
+		return ErrNotRunning
+	}
+	switch k {
+	case InterruptionInternal:
+		t.interruptions.Internal++
+	case InterruptionExternal:
+		t.interruptions.External++
+	default:
+		t.mu.Unlock()
+		return fmt.Errorf("app: unknown interruption kind %q", k)
+	}
+	e := t.event(t.state, ReasonInterrupted, t.clock.Now())
+	e.End = t.end
+	t.mu.Unlock()
+
+	t.notifySubscribers(e)
+	return nil
+}
+
+// Interruptions returns the interruptions recorded on the active session.
+func (t *timerApp) Interruptions() Interruptions {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.interruptions
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/history"
+)
+
+func TestRecordInterruption(t *testing.T) {
+	a, c := newFakeApp(t)
+	store := history.NewMemoryStore()
+	RecordHistory(a, store)
+
+	var events []Event
+	a.SubscribeEvents(func(e Event) { events = append(events, e) })
+	var states []State
+	a.SubscribeStateChange(func(s State) { states = append(states, s) })
+
+	if err := a.RecordInterruption(InterruptionInternal); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("expected ErrNotRunning while idle, got %v", err)
+	}
+
+	a.StartPomodoro()
+	for _, k := range []InterruptionKind{InterruptionInternal, InterruptionExternal, InterruptionExternal} {
+		if err := a.RecordInterruption(k); err != nil {
+			t.Fatalf("record %s: %v", k, err)
+		}
+	}
+	if err := a.RecordInterruption("phone"); err == nil {
+		t.Fatal("expected an unknown kind to be rejected")
+	}
+	want := Interruptions{Internal: 1, External: 2}
+	if got := a.Interruptions(); got != want {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if len(states) != 1 {
+		t.Fatalf("expected interruptions not to change state, got %v", states)
+	}
+
+	a.Pause()
+	if err := a.RecordInterruption(InterruptionInternal); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("expected ErrNotRunning while paused, got %v", err)
+	}
+	a.Resume()
+
+	c.Advance(DefaultPomodoro)
+	end := events[len(events)-1]
+	if end.Reason != ReasonCompleted || end.Interruptions != want {
+		t.Fatalf("expected final counts on the completed event, got %+v", end)
+	}
+	recs, _ := store.Load()
+	if len(recs) != 1 || recs[0].InternalInterruptions != 1 || recs[0].ExternalInterruptions != 2 {
+		t.Fatalf("expected counts in history, got %+v", recs)
+	}
+
+	// counts start over with the next session
+	a.StartPomodoro()
+	if got := a.Interruptions(); got.Total() != 0 {
+		t.Fatalf("expected no interruptions on a new pomodoro, got %v", got)
+	}
+	c.Advance(time.Minute)
+}
@@ -0,0 +1,39 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+)
+
+// KindBreak names the next break of the cycle in `StartNamed`.
+const KindBreak = "break"
+
+// ErrUnknownKind is returned by `StartNamed` for a kind it does not know.
+var ErrUnknownKind = errors.New("unknown session kind")
+
+// StartNamed starts the session named by kind on a: "pomodoro",
+// "short-break", "long-break", or "break" for the next break of the
+// cycle. Only a pomodoro can have a task. It serves control interfaces
+// that receive the kind as text.
+func StartNamed(a App, kind, task string) error {
+	if task != "" && kind != string(KindPomodoro) {
+		return errors.New("only a pomodoro can have a task")
+	}
+	switch kind {
+	case string(KindPomodoro):
+		if task != "" {
+			a.StartPomodoroFor(task)
+		} else {
+			a.StartPomodoro()
+		}
+	case string(KindShortBreak):
+		a.StartShortBreak()
+	case string(KindLongBreak):
+		a.StartLongBreak()
+	case KindBreak:
+		a.StartBreak()
+	default:
+		return fmt.Errorf("%w %q", ErrUnknownKind, kind)
+	}
+	return nil
+}
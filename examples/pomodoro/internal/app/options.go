@@ -0,0 +1,161 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/clock"
+	"github.com/co0p/4dc/examples/pomodoro/internal/history"
+)
+
+// AutoAdvance controls whether the next session starts by itself when a
+// session completes.
+type AutoAdvance int
+
+const (
+	// AutoAdvanceOff returns to idle after every session.
+	AutoAdvanceOff AutoAdvance = iota
+	// AutoAdvanceBreaks starts the next break of the cycle when a
+	// pomodoro completes. Breaks still return to idle.
+	AutoAdvanceBreaks
+	// AutoAdvanceAll also starts the next pomodoro when a break
+	// completes, running the cycle hands-free.
+	AutoAdvanceAll
+)
+
+// String returns a human-readable policy name.
+func (p AutoAdvance) String() string {
+	switch p {
+	case AutoAdvanceOff:
+		return "off"
+	case AutoAdvanceBreaks:
+		return "breaks"
+	case AutoAdvanceAll:
+		return "all"
+	default:
+		return fmt.Sprintf("AutoAdvance(%d)", int(p))
+	}
+}
+
+// Option configures an App built by `NewWithOptions`.
+type Option func(*options)
+
+// options collects the values set by Option functions.
+type options struct {
+	settings Settings
+	clock    clock.Clock
+	store    history.Store
+	storeSet bool
+	logger   *log.Logger
+}
+
+// WithPomodoro sets the pomodoro duration.
+func WithPomodoro(d time.Duration) Option {
+	return func(o *options) { o.settings.Pomodoro = d }
+}
+
+// WithShortBreak sets the short break duration.
+func WithShortBreak(d time.Duration) Option {
+	return func(o *options) { o.settings.ShortBreak = d }
+}
+
+// WithLongBreak sets the long break duration.
+func WithLongBreak(d time.Duration) Option {
+	return func(o *options) { o.settings.LongBreak = d }
+}
+
+// WithCycleLength sets the number of pomodoros after which `StartBreak`
+// picks a long break.
+func WithCycleLength(n int) Option {
+	return func(o *options) { o.settings.CycleLength = n }
+}
+
+// WithSettings sets every duration and cycle setting at once. Zero
+// fields of s use the package defaults.
+func WithSettings(s Settings) Option {
+	return func(o *options) { o.settings = s.withDefaults() }
+}
+
+// WithClock measures time with c instead of the system clock.
+func WithClock(c clock.Clock) Option {
+	return func(o *options) { o.clock = c }
+}
+
+// WithHistory records every finished session in store, see
+// `RecordHistory`.
+func WithHistory(store history.Store) Option {
+	return func(o *options) {
+		o.store = store
+		o.storeSet = true
+	}
+}
+
+// WithLogger logs session transitions and history failures to l. A nil
+// logger disables logging.
+func WithLogger(l *log.Logger) Option {
+	return func(o *options) { o.logger = l }
+}
+
+// WithAutoAdvance sets what happens when a session completes.
+func WithAutoAdvance(p AutoAdvance) Option {
+	return func(o *options) { o.settings.AutoAdvance = p }
+}
+
+// NewWithOptions creates a new App configured by opts. Unset values use
+// the package defaults. It returns an error describing every invalid
+// option, for example a non-positive duration.
+func NewWithOptions(opts ...Option) (App, error) {
+	o := options{settings: DefaultSettings(), clock: clock.Real()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+
+	a := newTimerApp(o.clock, o.settings)
+	if o.logger != nil {
+		a.SubscribeEvents(func(e Event) { logEvent(o.logger, e) })
+	}
+	if o.store != nil {
+		recordHistory(a, o.store, o.logger)
+	}
+	return a, nil
+}
+
+// validate reports all invalid options at once.
+func (o options) validate() error {
+	var errs []error
+	positive := func(name string, d time.Duration) {
+		if d <= 0 {
+			errs = append(errs, fmt.Errorf("app: %s duration must be positive, got %s", name, d))
+		}
+	}
+	positive("pomodoro", o.settings.Pomodoro)
+	positive("short break", o.settings.ShortBreak)
+	positive("long break", o.settings.LongBreak)
+	if o.settings.CycleLength < 1 {
+		errs = append(errs, fmt.Errorf("app: cycle length must be at least 1, got %d", o.settings.CycleLength))
+	}
+	if o.settings.AutoAdvance < AutoAdvanceOff || o.settings.AutoAdvance > AutoAdvanceAll {
+		errs = append(errs, fmt.Errorf("app: unknown auto-advance policy %s", o.settings.AutoAdvance))
+	}
+	if o.clock == nil {
+		errs = append(errs, errors.New("app: clock must not be nil"))
+	}
+	if o.storeSet && o.store == nil {
+		errs = append(errs, errors.New("app: history store must not be nil"))
+	}
+	return errors.Join(errs...)
+}
+
+// logEvent writes a one-line description of e to l.
+func logEvent(l *log.Logger, e Event) {
+	if e.Reason == ReasonStarted {
+		l.Printf("%s started (%s)", e.Kind, e.Planned)
+		return
+	}
+	l.Printf("%s %s: %s -> %s", e.Kind, e.Reason, e.From, e.To)
+}
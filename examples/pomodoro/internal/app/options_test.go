@@ -0,0 +1,148 @@
+package app
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/clock"
+	"github.com/co0p/4dc/examples/pomodoro/internal/history"
+)
+
+func TestNewWithOptionsDefaults(t *testing.T) {
+	a, err := NewWithOptions()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ta := a.(*timerApp)
+	if ta.pomodoroDuration != DefaultPomodoro || ta.breakDuration != DefaultShortBreak ||
+		ta.longBreakDuration != DefaultLongBreak || ta.cycleLength != DefaultCycleLength {
+		t.Fatalf("expected defaults, got %+v", ta)
+	}
+}
+
+func TestWithSettings(t *testing.T) {
+	a, err := NewWithOptions(
+		WithSettings(Settings{Pomodoro: 50 * time.Minute, CycleIdleReset: -1, AutoAdvance: AutoAdvanceAll}),
+		WithCycleLength(2),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ta := a.(*timerApp)
+	if ta.pomodoroDuration != 50*time.Minute || ta.breakDuration != DefaultShortBreak || ta.cycleLength != 2 ||
+		ta.cycleIdleReset != -1 || ta.autoAdvance != AutoAdvanceAll {
+		t.Fatalf("expected the settings with defaults and the later option applied, got %+v", ta)
+	}
+}
+
+func TestNewWithOptionsRejectsInvalid(t *testing.T) {
+	_, err := NewWithOptions(
+		WithPomodoro(0),
+		WithShortBreak(-time.Minute),
+		WithCycleLength(0),
+		WithClock(nil),
+		WithHistory(nil),
+	)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"pomodoro duration", "short break duration", "cycle length", "clock", "history store"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to mention %q, got:\n%v", want, err)
+		}
+	}
+}
+
+func TestNewWithOptionsWiresClockStoreAndLogger(t *testing.T) {
+	c := clock.NewFake(epoch)
+	store := history.NewMemoryStore()
+	var logs bytes.Buffer
+	a, err := NewWithOptions(
+		WithPomodoro(10*time.Minute),
+		WithClock(c),
+		WithHistory(store),
+		WithLogger(log.New(&logs, "", 0)),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	a.StartPomodoro()
+	c.Advance(10 * time.Minute)
+
+	recs, err := store.Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(recs) != 1 || recs[0].Outcome != history.OutcomeCompleted || recs[0].Planned != 10*time.Minute {
+		t.Fatalf("expected one completed 10m record, got %+v", recs)
+	}
+	want := "pomodoro started (10m0s)\npomodoro completed: PomodoroRunning -> Idle\n"
+	if logs.String() != want {
+		t.Fatalf("unexpected log:\n%s", logs.String())
+	}
+}
+
+func TestAutoAdvance(t *testing.T) {
+	c := clock.NewFake(epoch)
+	a, err := NewWithOptions(
+		WithClock(c),
+		WithCycleLength(2),
+		WithAutoAdvance(AutoAdvanceAll),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	a.StartPomodoro()
+	c.Advance(DefaultPomodoro)
+	if a.Kind() != KindShortBreak || a.Remaining() != DefaultShortBreak {
+		t.Fatalf("expected short break to start, got %s (%v)", a.Kind(), a.Remaining())
+	}
+	c.Advance(DefaultShortBreak)
+	if a.Kind() != KindPomodoro {
+		t.Fatalf("expected pomodoro to start, got %s", a.Kind())
+	}
+	c.Advance(DefaultPomodoro)
+	if a.Kind() != KindLongBreak {
+		t.Fatalf("expected long break after a full cycle, got %s", a.Kind())
+	}
+}
+
+func TestAutoAdvanceBreaksOnly(t *testing.T) {
+	c := clock.NewFake(epoch)
+	a, err := NewWithOptions(WithClock(c), WithAutoAdvance(AutoAdvanceBreaks))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var events []Event
+	a.SubscribeEvents(func(e Event) { events = append(events, e) })
+
+	a.StartPomodoro()
+	c.Advance(DefaultPomodoro)
+	c.Advance(DefaultShortBreak)
+	if a.State() != StateIdle {
+		t.Fatalf("expected idle after the break, got %s", a.State())
+	}
+
+	// started, completed, started (break), completed
+	if len(events) != 4 || events[2].Reason != ReasonStarted || events[2].From != StateIdle ||
+		events[2].Kind != KindShortBreak {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+}
+
+func TestNewCompatibility(t *testing.T) {
+	a := New(time.Minute, 2*time.Minute, 3*time.Minute, time.Hour).(*timerApp)
+	if a.pomodoroDuration != time.Minute || a.breakDuration != 2*time.Minute || a.longBreakDuration != 3*time.Minute {
+		t.Fatalf("unexpected durations: %v %v %v", a.pomodoroDuration, a.breakDuration, a.longBreakDuration)
+	}
+	a = New(0, -time.Second).(*timerApp)
+	if a.pomodoroDuration != DefaultPomodoro || a.breakDuration != DefaultShortBreak {
+		t.Fatalf("expected defaults for non-positive durations, got %v %v", a.pomodoroDuration, a.breakDuration)
+	}
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPauseFreezesRemaining(t *testing.T) {
+	a, c := newFakeApp(t)
+
+	a.StartPomodoro()
+	c.Advance(5 * time.Minute)
+	a.Pause()
+	if a.State() != StatePaused {
+		t.Fatalf("expected paused, got %s", a.State())
+	}
+	if a.Kind() != KindPomodoro {
+		t.Fatalf("expected paused kind pomodoro, got %q", a.Kind())
+	}
+	if rem := a.Remaining(); rem != 20*time.Minute {
+		t.Fatalf("expected remaining 20m, got %v", rem)
+	}
+
+	// well past the original end: the paused session must not complete
+	c.Advance(time.Hour)
+	if a.State() != StatePaused {
+		t.Fatalf("expected still paused, got %s", a.State())
+	}
+	if rem := a.Remaining(); rem != 20*time.Minute {
+		t.Fatalf("expected frozen remaining 20m, got %v", rem)
+	}
+}
+
+func TestResumeRearmsWithLeftover(t *testing.T) {
+	a, c := newFakeApp(t)
+
+	var transitions []State
+	a.OnStateChange(func(s State) { transitions = append(transitions, s) })
+
+	a.StartShortBreak()
+	c.Advance(2 * time.Minute)
+	a.Pause()
+	c.Advance(30 * time.Minute)
+
+	a.Resume()
+	if a.State() != StateBreakRunning {
+		t.Fatalf("expected break running after resume, got %s", a.State())
+	}
+	if a.Kind() != KindShortBreak {
+		t.Fatalf("expected short break kind, got %q", a.Kind())
+	}
+	if rem := a.Remaining(); rem != 3*time.Minute {
+		t.Fatalf("expected remaining 3m, got %v", rem)
+	}
+
+	c.Advance(3 * time.Minute)
+	want := []State{StateBreakRunning, StatePaused, StateBreakRunning, StateIdle}
+	if len(transitions) != len(want) {
+		t.Fatalf("expected transitions %v, got %v", want, transitions)
+	}
+	for i, w := range want {
+		if transitions[i] != w {
+			t.Fatalf("transition %d: expected %s, got %s", i, w, transitions[i])
+		}
+	}
+	if a.Kind() != "" {
+		t.Fatalf("expected no kind after completion, got %q", a.Kind())
+	}
+}
+
+func TestPauseAndResumeNoOps(t *testing.T) {
+	a := New(50*time.Millisecond, 20*time.Millisecond)
+
+	// nothing to pause or resume while idle
+	a.Pause()
+	a.Resume()
+	if a.State() != StateIdle {
+		t.Fatalf("expected idle, got %s", a.State())
+	}
+
+	// resume while running is a no-op
+	a.StartPomodoro()
+	a.Resume()
+	if a.State() != StatePomodoroRunning {
+		t.Fatalf("expected pomodoro running, got %s", a.State())
+	}
+}
@@ -7,30 +7,27 @@ import (
 )
 
 func TestRemainingBehaviorPomodoro(t *testing.T) {
-	// use short durations for test
-	a := New(50*time.Millisecond, 20*time.Millisecond)
+	a, c := newFakeApp(t)
 
 	a.StartPomodoro()
-	rem := a.Remaining()
-	if rem <= 0 || rem > 50*time.Millisecond {
-		t.Fatalf("expected remaining in (0,50ms], got %v", rem)
+	if rem := a.Remaining(); rem != DefaultPomodoro {
+		t.Fatalf("expected remaining %v, got %v", DefaultPomodoro, rem)
 	}
 
-	time.Sleep(20 * time.Millisecond)
-	rem2 := a.Remaining()
-	if rem2 >= rem {
-		t.Fatalf("expected remaining to decrease; before=%v after=%v", rem, rem2)
+	c.Advance(10 * time.Minute)
+	if rem := a.Remaining(); rem != DefaultPomodoro-10*time.Minute {
+		t.Fatalf("expected remaining to decrease to %v, got %v", DefaultPomodoro-10*time.Minute, rem)
 	}
 
 	// wait for completion
-	time.Sleep(40 * time.Millisecond)
+	c.Advance(20 * time.Minute)
 	if a.Remaining() != 0 {
 		t.Fatalf("expected remaining 0 after finish, got %v", a.Remaining())
 	}
 }
 
 func TestRemainingAfterCancel(t *testing.T) {
-	a := New(100*time.Millisecond, 50*time.Millisecond)
+	a, _ := newFakeApp(t)
 	a.StartPomodoro()
 	if a.Remaining() == 0 {
 		t.Fatalf("expected non-zero remaining after start")
@@ -0,0 +1,78 @@
+package app
+
+import "time"
+
+// Default durations and cycle configuration used when an option or
+// setting is left unset.
+const (
+	DefaultPomodoro       = 25 * time.Minute
+	DefaultShortBreak     = 5 * time.Minute
+	DefaultLongBreak      = 25 * time.Minute
+	DefaultCycleLength    = 4
+	DefaultCycleIdleReset = time.Hour
+)
+
+// Settings configures session durations and the pomodoro cycle. Zero
+// fields fall back to the package defaults.
+type Settings struct {
+	Pomodoro   time.Duration
+	ShortBreak time.Duration
+	LongBreak  time.Duration
+	// CycleLength is the number of completed pomodoros after which
+	// `StartBreak` picks a long break.
+	CycleLength int
+	// CycleIdleReset resets the cycle count when no session has run for
+	// at least this long. A negative value disables the reset.
+	CycleIdleReset time.Duration
+	// AutoAdvance sets whether the next session starts by itself when a
+	// session completes. The zero value returns to idle.
+	AutoAdvance AutoAdvance
+}
+
+// DefaultSettings returns the settings used by `New()`.
+func DefaultSettings() Settings {
+	return Settings{
+		Pomodoro:       DefaultPomodoro,
+		ShortBreak:     DefaultShortBreak,
+		LongBreak:      DefaultLongBreak,
+		CycleLength:    DefaultCycleLength,
+		CycleIdleReset: DefaultCycleIdleReset,
+	}
+}
+
+// withDefaults returns s with zero fields replaced by their defaults.
+func (s Settings) withDefaults() Settings {
+	d := DefaultSettings()
+	if s.Pomodoro == 0 {
+		s.Pomodoro = d.Pomodoro
+	}
+	if s.ShortBreak == 0 {
+		s.ShortBreak = d.ShortBreak
+	}
+	if s.LongBreak == 0 {
+		s.LongBreak = d.LongBreak
+	}
+	if s.CycleLength == 0 {
+		s.CycleLength = d.CycleLength
+	}
+	if s.CycleIdleReset == 0 {
+		s.CycleIdleReset = d.CycleIdleReset
+	}
+	return s
+}
+
+// UpdateSettings replaces all settings, including CycleIdleReset and
+// AutoAdvance, so s should be complete. Zero fields of s use the
+// package defaults. A running or paused session is not affected: it
+// keeps its end time or remainder.
+func (t *timerApp) UpdateSettings(s Settings) {
+	s = s.withDefaults()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pomodoroDuration = s.Pomodoro
+	t.breakDuration = s.ShortBreak
+	t.longBreakDuration = s.LongBreak
+	t.cycleLength = s.CycleLength
+	t.cycleIdleReset = s.CycleIdleReset
+	t.autoAdvance = s.AutoAdvance
+}
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUpdateSettingsKeepsRunningSession(t *testing.T) {
+	a, c := newFakeApp(t)
+
+	a.StartPomodoro()
+	c.Advance(5 * time.Minute)
+
+	a.UpdateSettings(Settings{Pomodoro: 50 * time.Minute, ShortBreak: 10 * time.Minute, CycleLength: 2})
+
+	// the running pomodoro keeps its original end time
+	if rem := a.Remaining(); rem != 20*time.Minute {
+		t.Fatalf("expected running session untouched (20m left), got %v", rem)
+	}
+	if got := a.Cycle().Length; got != 2 {
+		t.Fatalf("expected new cycle length 2, got %d", got)
+	}
+
+	c.Advance(20 * time.Minute)
+	a.StartBreak()
+	if rem := a.Remaining(); rem != 10*time.Minute {
+		t.Fatalf("expected new short break duration, got %v", rem)
+	}
+	c.Advance(10 * time.Minute)
+
+	a.StartPomodoro()
+	if rem := a.Remaining(); rem != 50*time.Minute {
+		t.Fatalf("expected new pomodoro duration, got %v", rem)
+	}
+
+	// zero fields fall back to the defaults
+	a.UpdateSettings(Settings{})
+	if got := a.Cycle().Length; got != DefaultCycleLength {
+		t.Fatalf("expected default cycle length, got %d", got)
+	}
+}
@@ -0,0 +1,282 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/atomicfile"
+)
+
+// Snapshot captures the timer state so an in-flight session can survive a
+// restart of the process. See `App.Snapshot` and `App.Restore`.
+type Snapshot struct {
+	State State
+	Kind  Kind
+	// Started, Planned and End describe the session; End is zero while
+	// paused.
+	Started time.Time
+	Planned time.Duration
+	End     time.Time
+	// Remaining is the time left when the snapshot was taken. For a
+	// paused session it is the frozen remainder.
+	Remaining time.Duration
+	// Ran is how long the session had run when the snapshot was taken,
+	// see `Event.Elapsed`.
+	Ran time.Duration
+	// Cycle is the progress through the pomodoro cycle and LastEnded when
+	// the app last returned to idle.
+	Cycle     Cycle
+	LastEnded time.Time
+	// Task is the label of the session, if any.
+	Task string
+	// Interruptions recorded on the session so far.
+	Interruptions Interruptions
+}
+
+// snapshotJSON is the on-disk form of a Snapshot. Durations are stored as
+// Go duration strings to keep the file readable.
+type snapshotJSON struct {
+	State     State     `json:"state"`
+	Kind      Kind      `json:"kind,omitempty"`
+	Started   time.Time `json:"started"`
+	Planned   string    `json:"planned"`
+	End       time.Time `json:"end"`
+	Remaining string    `json:"remaining"`
+	Ran       string    `json:"ran,omitempty"`
+	Completed int       `json:"completed"`
+	LastEnded time.Time `json:"last_ended"`
+	Task      string    `json:"task,omitempty"`
+	Internal  int       `json:"internal_interruptions,omitempty"`
+	External  int       `json:"external_interruptions,omitempty"`
+}
+
+// MarshalJSON encodes s in the state file format.
+func (s Snapshot) MarshalJSON() ([]byte, error) {
+	return json.Marshal(snapshotJSON{
+		State:     s.State,
+		Kind:      s.Kind,
+		Started:   s.Started,
+		Planned:   s.Planned.String(),
+		End:       s.End,
+		Remaining: s.Remaining.String(),
+		Ran:       s.Ran.String(),
+		Completed: s.Cycle.Completed,
+		LastEnded: s.LastEnded,
+		Task:      s.Task,
+		Internal:  s.Interruptions.Internal,
+		External:  s.Interruptions.External,
+	})
+}
+
+// UnmarshalJSON decodes s from the state file format.
+func (s *Snapshot) UnmarshalJSON(b []byte) error {
+	var j snapshotJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	planned, err := time.ParseDuration(j.Planned)
+	if err != nil {
+		return fmt.Errorf("planned: %w", err)
+	}
+	rem, err := time.ParseDuration(j.Remaining)
+	if err != nil {
+		return fmt.Errorf("remaining: %w", err)
+	}
+	var ran time.Duration
+	if j.Ran != "" {
+		if ran, err = time.ParseDuration(j.Ran); err != nil {
+			return fmt.Errorf("ran: %w", err)
+		}
+	}
+	*s = Snapshot{
+		State:         j.State,
+		Kind:          j.Kind,
+		Started:       j.Started,
+		Planned:       planned,
+		End:           j.End,
+		Remaining:     rem,
+		Ran:           ran,
+		Cycle:         Cycle{Completed: j.Completed},
+		LastEnded:     j.LastEnded,
+		Task:          j.Task,
+		Interruptions: Interruptions{Internal: j.Internal, External: j.External},
+	}
+	return nil
+}
+
+// validate checks that s describes a session the app can restore.
+func (s Snapshot) validate() error {
+	switch s.State {
+	case StateIdle:
+		return nil
+	case StatePomodoroRunning, StateBreakRunning:
+		if s.End.IsZero() {
+			return errors.New("running session without end time")
+		}
+	case StatePaused:
+		if s.Remaining <= 0 {
+			return errors.New("paused session without remaining time")
+		}
+	default:
+		return fmt.Errorf("unknown state %q", s.State)
+	}
+	switch s.Kind {
+	case KindPomodoro, KindShortBreak, KindLongBreak:
+	default:
+		return fmt.Errorf("unknown session kind %q", s.Kind)
+	}
+	if s.State != StatePaused && s.State != runningState(s.Kind) {
+		return fmt.Errorf("state %s does not match kind %s", s.State, s.Kind)
+	}
+	return nil
+}
+
+// Snapshot returns the current timer state.
+func (t *timerApp) Snapshot() Snapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.clock.Now()
+	t.resetStaleCycle(now)
+	s := Snapshot{
+		State:         t.state,
+		Kind:          t.kind,
+		Started:       t.started,
+		Planned:       t.planned,
+		Cycle:         Cycle{Completed: t.completed, Length: t.cycleLength},
+		LastEnded:     t.lastEnded,
+		Task:          t.task,
+		Interruptions: t.interruptions,
+		Ran:           t.elapsed(now),
+	}
+	switch t.state {
+	case StatePaused:
+		s.Remaining = t.paused
+	case StatePomodoroRunning, StateBreakRunning:
+		s.End = t.end
+		if rem := t.end.Sub(now); rem > 0 {
+			s.Remaining = rem
+		}
+	}
+	return s
+}
+
+// Restore brings an idle app back to the state captured in s. A running
+// session is re-armed until its original end time and a paused one keeps
+// its remainder. A running session whose end time has already passed is
+// completed when it ended within the cycle idle gap and expired
+// otherwise; either way the app stays idle. The cycle progress is
+// restored too.
+func (t *timerApp) Restore(s Snapshot) error {
+	if err := s.validate(); err != nil {
+		return fmt.Errorf("app: invalid snapshot: %w", err)
+	}
+
+	t.mu.Lock()
+	if t.state != StateIdle {
+		t.mu.Unlock()
+		return errors.New("app: restore requires an idle app")
+	}
+	now := t.clock.Now()
+	t.completed = s.Cycle.Completed
+	t.lastEnded = s.LastEnded
+	if s.State == StateIdle {
+		t.mu.Unlock()
+		return nil
+	}
+
+	t.kind = s.Kind
+	t.planned = s.Planned
+	t.started = s.Started
+	t.ran = s.Ran
+	t.task = s.Task
+	t.interruptions = s.Interruptions
+	if s.Task != "" {
+		t.lastTask = s.Task
+	}
+
+	var e Event
+	switch {
+	case s.State == StatePaused:
+		e = t.event(StatePaused, ReasonRestored, now)
+		t.paused = s.Remaining
+		t.state = StatePaused
+	case s.End.After(now):
+		// the time the app was not running does not count as run
+		e = t.event(runningState(s.Kind), ReasonRestored, now)
+		t.since = now
+		t.arm(s.End.Sub(now))
+	default:
+		// the session ran out while the app was not running; it ran for
+		// as long as it had when the app stopped
+		t.state = runningState(s.Kind)
+		t.since = s.End
+		reason := ReasonCompleted
+		if t.cycleIdleReset >= 0 && now.Sub(s.End) >= t.cycleIdleReset {
+			reason = ReasonExpired
+		}
+		e = t.event(StateIdle, reason, s.End)
+		t.endSession(reason, s.End)
+		t.clearSession()
+	}
+	t.mu.Unlock()
+
+	t.notifySubscribers(e)
+	return nil
+}
+
+// ReadSnapshotFile reads a snapshot written by `WriteSnapshotFile`. A
+// missing file yields an idle snapshot.
+func ReadSnapshotFile(path string) (Snapshot, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Snapshot{State: StateIdle}, nil
+	}
+	if err != nil {
+		return Snapshot{}, err
+	}
+	var s Snapshot
+	if err := json.Unmarshal(b, &s); err != nil {
+		return Snapshot{}, fmt.Errorf("app: %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// WriteSnapshotFile atomically replaces the file at path with s.
+func WriteSnapshotFile(path string, s Snapshot) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.Write(path, append(b, '\n'), 0o600)
+}
+
+// PersistSnapshots writes a snapshot of a to path after every transition
+// so the active session can be restored after a restart. On shutdown the
+// file keeps describing the session that was active when the app
+// stopped, updated with how long it ran. Write failures are logged. The
+// returned function stops persisting.
+func PersistSnapshots(a App, path string) func() {
+	var mu sync.Mutex
+	var last Snapshot
+	return a.SubscribeEvents(func(e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		s := a.Snapshot()
+		if e.Reason == ReasonShutdown {
+			if last.State == "" || last.State == StateIdle {
+				return
+			}
+			s = last
+			s.Ran = e.Elapsed
+		}
+		if err := WriteSnapshotFile(path, s); err != nil {
+			log.Printf("state: write failed: %v", err)
+		}
+		last = s
+	})
+}
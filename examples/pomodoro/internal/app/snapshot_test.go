@@ -0,0 +1,179 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/clock"
+)
+
+func TestRestoreRunningSessionFromFile(t *testing.T) {
+	a, c := newFakeApp(t)
+	a.StartPomodoro()
+	c.Advance(10 * time.Minute)
+
+	path := filepath.Join(t.TempDir(), "session.json")
+	if err := WriteSnapshotFile(path, a.Snapshot()); err != nil {
+		t.Fatal(err)
+	}
+	snap, err := ReadSnapshotFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the process restarts two minutes later
+	c2 := clock.NewFake(epoch.Add(12 * time.Minute))
+	b := newTimerApp(c2, DefaultSettings())
+	var events []Event
+	b.SubscribeEvents(func(e Event) { events = append(events, e) })
+
+	if err := b.Restore(snap); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if b.State() != StatePomodoroRunning || b.Kind() != KindPomodoro {
+		t.Fatalf("expected restored pomodoro, got %s/%s", b.State(), b.Kind())
+	}
+	if rem := b.Remaining(); rem != 13*time.Minute {
+		t.Fatalf("expected original end to be kept (13m left), got %v", rem)
+	}
+	if len(events) != 1 || events[0].Reason != ReasonRestored || events[0].Started != epoch {
+		t.Fatalf("unexpected restore events %+v", events)
+	}
+
+	c2.Advance(13 * time.Minute)
+	if b.State() != StateIdle || b.Cycle().Completed != 1 {
+		t.Fatalf("expected restored pomodoro to complete, got %s %s", b.State(), b.Cycle())
+	}
+	// the two minutes the app was not running do not count
+	if done := events[len(events)-1]; done.Reason != ReasonCompleted || done.Elapsed != 23*time.Minute {
+		t.Fatalf("expected the pomodoro to complete after running 23m, got %+v", done)
+	}
+}
+
+func TestRestorePausedSession(t *testing.T) {
+	a, c := newFakeApp(t)
+	a.StartShortBreak()
+	c.Advance(time.Minute)
+	a.Pause()
+
+	c2 := clock.NewFake(epoch.Add(3 * time.Hour))
+	b := newTimerApp(c2, DefaultSettings())
+	if err := b.Restore(a.Snapshot()); err != nil {
+		t.Fatal(err)
+	}
+	if b.State() != StatePaused || b.Kind() != KindShortBreak || b.Remaining() != 4*time.Minute {
+		t.Fatalf("expected paused short break with 4m left, got %s/%s %v", b.State(), b.Kind(), b.Remaining())
+	}
+	b.Resume()
+	c2.Advance(4 * time.Minute)
+	if b.State() != StateIdle {
+		t.Fatalf("expected idle after resumed break, got %s", b.State())
+	}
+}
+
+func TestRestoreEndedSession(t *testing.T) {
+	snap := Snapshot{
+		State:   StatePomodoroRunning,
+		Kind:    KindPomodoro,
+		Started: epoch,
+		Planned: DefaultPomodoro,
+		End:     epoch.Add(DefaultPomodoro),
+		Cycle:   Cycle{Completed: 2},
+	}
+
+	tests := []struct {
+		name      string
+		restartAt time.Duration
+		reason    Reason
+		completed int
+	}{
+		{"ended recently", 30 * time.Minute, ReasonCompleted, 3},
+		// the idle gap since the session ended also resets the cycle
+		{"ended long ago", 25*time.Minute + DefaultCycleIdleReset, ReasonExpired, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTimerApp(clock.NewFake(epoch.Add(tt.restartAt)), DefaultSettings())
+			var got Event
+			a.SubscribeEvents(func(e Event) { got = e })
+
+			if err := a.Restore(snap); err != nil {
+				t.Fatal(err)
+			}
+			if a.State() != StateIdle {
+				t.Fatalf("expected idle, got %s", a.State())
+			}
+			if got.Reason != tt.reason || !got.At.Equal(snap.End) || !got.Ends() {
+				t.Fatalf("unexpected event %+v", got)
+			}
+			if n := a.Snapshot().Cycle.Completed; n != tt.completed {
+				t.Fatalf("expected %d completed, got %d", tt.completed, n)
+			}
+		})
+	}
+}
+
+func TestRestoreRejectsInvalidInput(t *testing.T) {
+	a, _ := newFakeApp(t)
+
+	bad := []Snapshot{
+		{State: "Bogus"},
+		{State: StatePomodoroRunning, Kind: KindPomodoro},
+		{State: StatePaused, Kind: KindPomodoro},
+		{State: StateBreakRunning, Kind: KindPomodoro, End: epoch},
+	}
+	for _, s := range bad {
+		if err := a.Restore(s); err == nil {
+			t.Fatalf("expected error restoring %+v", s)
+		}
+	}
+
+	a.StartPomodoro()
+	if err := a.Restore(Snapshot{State: StateIdle}); err == nil {
+		t.Fatal("expected error restoring into a running app")
+	}
+}
+
+func TestPersistSnapshotsKeepsSessionOnShutdown(t *testing.T) {
+	a, c := newFakeApp(t)
+	path := filepath.Join(t.TempDir(), "session.json")
+	defer PersistSnapshots(a, path)()
+
+	snap, err := ReadSnapshotFile(path)
+	if err != nil || snap.State != StateIdle {
+		t.Fatalf("expected idle snapshot for missing file, got %+v, %v", snap, err)
+	}
+
+	a.StartPomodoro()
+	c.Advance(5 * time.Minute)
+	a.Pause()
+	_ = a.Shutdown(context.Background())
+
+	snap, err = ReadSnapshotFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.State != StatePaused || snap.Remaining != 20*time.Minute || !snap.Started.Equal(epoch) {
+		t.Fatalf("expected the paused session to survive shutdown, got %+v", snap)
+	}
+}
+
+func TestPersistSnapshotsRecordsRunTimeOnShutdown(t *testing.T) {
+	a, c := newFakeApp(t)
+	path := filepath.Join(t.TempDir(), "session.json")
+	defer PersistSnapshots(a, path)()
+
+	a.StartPomodoro()
+	c.Advance(7 * time.Minute)
+	_ = a.Shutdown(context.Background())
+
+	snap, err := ReadSnapshotFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.State != StatePomodoroRunning || snap.Ran != 7*time.Minute || !snap.End.Equal(epoch.Add(DefaultPomodoro)) {
+		t.Fatalf("expected the running session with 7m run, got %+v", snap)
+	}
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/history"
+)
+
+func TestStartPomodoroForLabelsSession(t *testing.T) {
+	a, c := newFakeApp(t)
+	store := history.NewMemoryStore()
+	RecordHistory(a, store)
+
+	var events []Event
+	a.SubscribeEvents(func(e Event) { events = append(events, e) })
+
+	a.StartPomodoroFor("ABC-123")
+	if a.Task() != "ABC-123" {
+		t.Fatalf("expected task ABC-123, got %q", a.Task())
+	}
+	if events[0].Task != "ABC-123" {
+		t.Fatalf("expected the started event to carry the task, got %+v", events[0])
+	}
+
+	// same task: no-op; plain StartPomodoro keeps the running one
+	a.StartPomodoroFor("ABC-123")
+	a.StartPomodoro()
+	if len(events) != 1 {
+		t.Fatalf("expected no new events, got %+v", events[1:])
+	}
+
+	// another task replaces the running pomodoro
+	c.Advance(time.Minute)
+	a.StartPomodoroFor("XYZ-9")
+	if a.Task() != "XYZ-9" || a.Remaining() != DefaultPomodoro {
+		t.Fatalf("expected a fresh pomodoro for XYZ-9, got %q %v", a.Task(), a.Remaining())
+	}
+
+	c.Advance(DefaultPomodoro)
+	if a.Task() != "" {
+		t.Fatalf("expected no task when idle, got %q", a.Task())
+	}
+	recs, _ := store.Load()
+	if len(recs) != 2 || recs[0].Label != "ABC-123" || recs[1].Label != "XYZ-9" {
+		t.Fatalf("expected labelled history records, got %+v", recs)
+	}
+
+	// breaks are not labelled
+	a.StartShortBreak()
+	if a.Task() != "" {
+		t.Fatalf("expected an unlabelled break, got %q", a.Task())
+	}
+}
+
+func TestSnapshotKeepsTask(t *testing.T) {
+	a, c := newFakeApp(t)
+	a.StartPomodoroFor("ABC-123")
+	c.Advance(time.Minute)
+
+	b, err := json.Marshal(a.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(b, &snap); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, _ := newFakeApp(t)
+	if err := restored.Restore(snap); err != nil {
+		t.Fatal(err)
+	}
+	if restored.Task() != "ABC-123" {
+		t.Fatalf("expected restored task ABC-123, got %q", restored.Task())
+	}
+}
+
+func TestAutoAdvanceKeepsTask(t *testing.T) {
+	a, c := newFakeApp(t)
+	a.UpdateSettings(Settings{AutoAdvance: AutoAdvanceAll})
+
+	a.StartPomodoroFor("ABC-123")
+	c.Advance(DefaultPomodoro)
+	if a.Task() != "" {
+		t.Fatalf("expected an unlabelled break, got %q", a.Task())
+	}
+	c.Advance(DefaultShortBreak)
+	if a.Kind() != KindPomodoro || a.Task() != "ABC-123" {
+		t.Fatalf("expected the next pomodoro on ABC-123, got %s %q", a.Kind(), a.Task())
+	}
+}
@@ -0,0 +1,65 @@
+// Package apptest holds fixtures shared by the tests of packages built on
+// the app: an app driven by a fake clock, a buffer for logs written from
+// other goroutines and a helper to wait for asynchronous effects.
+//
+// The app, clock and history packages cannot use it from their own tests,
+// since it imports them.
+package apptest
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+	"github.com/co0p/4dc/examples/pomodoro/internal/clock"
+)
+
+// Epoch is the time fake clocks in tests start at.
+var Epoch = time.Date(2025, 12, 5, 9, 0, 0, 0, time.UTC)
+
+// NewApp returns an app with opts applied, driven by a fake clock set to
+// Epoch.
+func NewApp(t testing.TB, opts ...app.Option) (app.App, *clock.Fake) {
+	t.Helper()
+	c := clock.NewFake(Epoch)
+	a, err := app.NewWithOptions(append([]app.Option{app.WithClock(c)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a, c
+}
+
+// Eventually fails t unless cond becomes true within a second. what
+// describes the condition in the failure.
+func Eventually(t testing.TB, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// SyncBuffer is a bytes.Buffer safe for concurrent use.
+type SyncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// Write appends p to the buffer.
+func (b *SyncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// String returns the contents written so far.
+func (b *SyncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
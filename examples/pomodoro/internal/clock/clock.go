@@ -0,0 +1,68 @@
+// Package clock abstracts the passage of time for the pomodoro timer. The
+// real clock delegates to the `time` package; the `Fake` clock only moves
+// when told to, so full pomodoro cycles can be tested deterministically.
+package clock
+
+import "time"
+
+// Clock is the source of time used by the timer domain and the tray
+// updater.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTimer returns a timer that sends the time on its channel once d
+	// has elapsed.
+	NewTimer(d time.Duration) Timer
+	// AfterFunc calls f once d has elapsed. The returned timer can be used
+	// to cancel the call.
+	AfterFunc(d time.Duration, f func()) Timer
+	// NewTicker returns a ticker that sends the time on its channel every d.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is a single-shot timer created by a Clock.
+type Timer interface {
+	// C returns the channel on which the time is delivered. It is nil for
+	// timers created by AfterFunc.
+	C() <-chan time.Time
+	// Stop prevents the timer from firing. It reports whether the call
+	// stopped the timer, false if it already fired or was stopped.
+	Stop() bool
+}
+
+// Ticker is a repeating timer created by a Clock.
+type Ticker interface {
+	// C returns the channel on which ticks are delivered.
+	C() <-chan time.Time
+	// Stop turns off the ticker. No more ticks are sent after Stop.
+	Stop()
+}
+
+// Real returns a Clock backed by the `time` package.
+func Real() Clock { return realClock{} }
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{t: time.NewTimer(d)}
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return realTimer{t: time.AfterFunc(d, f)}
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{t: time.NewTicker(d)}
+}
+
+type realTimer struct{ t *time.Timer }
+
+func (r realTimer) C() <-chan time.Time { return r.t.C }
+func (r realTimer) Stop() bool          { return r.t.Stop() }
+
+type realTicker struct{ t *time.Ticker }
+
+func (r realTicker) C() <-chan time.Time { return r.t.C }
+func (r realTicker) Stop()               { r.t.Stop() }
@@ -0,0 +1,155 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is a manually driven Clock for tests. Time only moves forward when
+// `Advance` or `Set` is called; timers, tickers and AfterFunc callbacks
+// that become due fire in deadline order from within that call.
+//
+// AfterFunc callbacks run synchronously on the goroutine calling Advance,
+// so they must not call back into Advance.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeTimer
+}
+
+// NewFake returns a Fake clock set to start.
+func NewFake(start time.Time) *Fake {
+	return &Fake{now: start}
+}
+
+// Now returns the fake current time.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// NewTimer returns a timer that fires once the fake time has advanced by d.
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	return f.add(d, 0, nil)
+}
+
+// AfterFunc calls fn once the fake time has advanced by d.
+func (f *Fake) AfterFunc(d time.Duration, fn func()) Timer {
+	return f.add(d, 0, fn)
+}
+
+// NewTicker returns a ticker that fires every d of fake time.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	return fakeTicker{w: f.add(d, d, nil)}
+}
+
+// Pending returns the number of timers and tickers that have not fired
+// or been stopped yet.
+func (f *Fake) Pending() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+// Advance moves the fake time forward by d, firing everything that
+// becomes due on the way.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	target := f.now.Add(d)
+	f.mu.Unlock()
+	f.Set(target)
+}
+
+// Set moves the fake time forward to t, firing everything that becomes
+// due on the way. Moving backwards is a no-op.
+func (f *Fake) Set(t time.Time) {
+	for {
+		f.mu.Lock()
+		if len(f.waiters) == 0 || f.waiters[0].when.After(t) {
+			if t.After(f.now) {
+				f.now = t
+			}
+			f.mu.Unlock()
+			return
+		}
+		w := f.waiters[0]
+		f.waiters = f.waiters[1:]
+		if w.when.After(f.now) {
+			f.now = w.when
+		}
+		now := f.now
+		if w.period > 0 {
+			w.when = w.when.Add(w.period)
+			f.insert(w)
+		}
+		f.mu.Unlock()
+
+		if w.fn != nil {
+			w.fn()
+			continue
+		}
+		select {
+		case w.ch <- now:
+		default:
+			// like time.Ticker, drop ticks for slow receivers
+		}
+	}
+}
+
+func (f *Fake) add(d, period time.Duration, fn func()) *fakeTimer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w := &fakeTimer{f: f, when: f.now.Add(d), period: period, fn: fn}
+	if fn == nil {
+		w.ch = make(chan time.Time, 1)
+	}
+	f.insert(w)
+	return w
+}
+
+// insert adds w keeping waiters ordered by deadline. It must be called
+// with f.mu held.
+func (f *Fake) insert(w *fakeTimer) {
+	i := sort.Search(len(f.waiters), func(i int) bool {
+		return f.waiters[i].when.After(w.when)
+	})
+	f.waiters = append(f.waiters, nil)
+	copy(f.waiters[i+1:], f.waiters[i:])
+	f.waiters[i] = w
+}
+
+// remove drops w from the waiters and reports whether it was pending.
+func (f *Fake) remove(w *fakeTimer) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, x := range f.waiters {
+		if x == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type fakeTimer struct {
+	f      *Fake
+	when   time.Time
+	period time.Duration
+	fn     func()
+	ch     chan time.Time
+}
+
+func (w *fakeTimer) C() <-chan time.Time { return w.ch }
+
+func (w *fakeTimer) Stop() bool { return w.f.remove(w) }
+
+type fakeTicker struct{ w *fakeTimer }
+
+func (t fakeTicker) C() <-chan time.Time { return t.w.ch }
+
+func (t fakeTicker) Stop() { t.w.f.remove(t.w) }
@@ -0,0 +1,89 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2025, 12, 5, 9, 0, 0, 0, time.UTC)
+
+func TestFakeTimerFiresOnAdvance(t *testing.T) {
+	c := NewFake(epoch)
+	tm := c.NewTimer(10 * time.Minute)
+
+	c.Advance(9 * time.Minute)
+	select {
+	case <-tm.C():
+		t.Fatal("timer fired early")
+	default:
+	}
+
+	c.Advance(time.Minute)
+	select {
+	case got := <-tm.C():
+		if !got.Equal(epoch.Add(10 * time.Minute)) {
+			t.Fatalf("unexpected fire time %v", got)
+		}
+	default:
+		t.Fatal("timer did not fire")
+	}
+	if tm.Stop() {
+		t.Fatal("Stop after firing should report false")
+	}
+}
+
+func TestFakeAfterFuncOrderAndStop(t *testing.T) {
+	c := NewFake(epoch)
+	var fired []string
+	c.AfterFunc(2*time.Second, func() { fired = append(fired, "b") })
+	c.AfterFunc(time.Second, func() { fired = append(fired, "a") })
+	stopped := c.AfterFunc(time.Second, func() { fired = append(fired, "x") })
+
+	if !stopped.Stop() {
+		t.Fatal("expected Stop to report a pending timer")
+	}
+	c.Advance(time.Hour)
+
+	if len(fired) != 2 || fired[0] != "a" || fired[1] != "b" {
+		t.Fatalf("unexpected firing order %v", fired)
+	}
+	if c.Pending() != 0 {
+		t.Fatalf("expected no pending timers, got %d", c.Pending())
+	}
+	if !c.Now().Equal(epoch.Add(time.Hour)) {
+		t.Fatalf("unexpected now %v", c.Now())
+	}
+}
+
+func TestFakeAfterFuncSeesDeadlineAsNow(t *testing.T) {
+	c := NewFake(epoch)
+	var at time.Time
+	c.AfterFunc(25*time.Minute, func() { at = c.Now() })
+
+	c.Advance(time.Hour)
+	if !at.Equal(epoch.Add(25 * time.Minute)) {
+		t.Fatalf("expected callback at deadline, got %v", at)
+	}
+}
+
+func TestFakeTickerRepeatsAndStops(t *testing.T) {
+	c := NewFake(epoch)
+	tk := c.NewTicker(10 * time.Second)
+
+	for i := 1; i <= 3; i++ {
+		c.Advance(10 * time.Second)
+		select {
+		case <-tk.C():
+		default:
+			t.Fatalf("missing tick %d", i)
+		}
+	}
+
+	tk.Stop()
+	c.Advance(time.Minute)
+	select {
+	case <-tk.C():
+		t.Fatal("tick after Stop")
+	default:
+	}
+}
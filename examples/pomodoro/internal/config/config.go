@@ -0,0 +1,244 @@
+// Package config loads the user configuration of the pomodoro app. The
+// configuration is a JSON file that may contain `//` comments, so the file
+// written by `pomodoro config print` documents itself. Values from the
+// file can be overridden with `POMODORO_*` environment variables.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/notify"
+	"github.com/co0p/4dc/examples/pomodoro/internal/paths"
+)
+
+// PathEnv names the environment variable that overrides the location of
+// the configuration file.
+const PathEnv = "POMODORO_CONFIG"
+
+// Config is the user configuration.
+type Config struct {
+	Pomodoro   time.Duration
+	ShortBreak time.Duration
+	LongBreak  time.Duration
+	// CycleLength is the number of pomodoros before a long break.
+	CycleLength int
+	// TitleFormat is a text/template rendering the tray title, for example
+	// "{{.Minutes}}m".
+	TitleFormat string
+	// TickInterval is how often the tray title is refreshed.
+	TickInterval time.Duration
+	// HTTPPort is the 127.0.0.1 port of the HTTP control API. Zero
+	// disables the API.
+	HTTPPort int
+	// HookTimeout bounds each run of a hook script and HookConcurrency
+	// is how many hooks may run at once.
+	HookTimeout     time.Duration
+	HookConcurrency int
+	// Webhooks receive a signed JSON payload on every state change.
+	Webhooks []Webhook
+	// NotifyBackend selects how completed sessions are announced, one of
+	// `notify.Backends`. PomodoroDone and BreakDone are the notifications
+	// shown when a pomodoro or a break completes.
+	NotifyBackend string
+	PomodoroDone  Message
+	BreakDone     Message
+}
+
+// Message is the text of a notification. Title and Body are Go
+// templates over `notify.Data`.
+type Message struct {
+	Title string
+	Body  string
+}
+
+// Webhook is an endpoint receiving state changes. Secret keys the HMAC
+// signature of each delivery.
+type Webhook struct {
+	URL    string
+	Secret string
+}
+
+// Default returns the configuration used when no file exists.
+func Default() Config {
+	return Config{
+		Pomodoro:        25 * time.Minute,
+		ShortBreak:      5 * time.Minute,
+		LongBreak:       25 * time.Minute,
+		CycleLength:     4,
+		TitleFormat:     "{{.Minutes}}m",
+		TickInterval:    10 * time.Second,
+		HookTimeout:     10 * time.Second,
+		HookConcurrency: 2,
+		NotifyBackend:   notify.BackendAuto,
+		PomodoroDone:    Message{Title: notify.DefaultPomodoroTitle, Body: notify.DefaultPomodoroBody},
+		BreakDone:       Message{Title: notify.DefaultBreakTitle, Body: notify.DefaultBreakBody},
+	}
+}
+
+// Path returns the configuration file location: the value of
+// `POMODORO_CONFIG` if set, otherwise `config.json` in the user config
+// directory.
+func Path() (string, error) {
+	if p := os.Getenv(PathEnv); p != "" {
+		return p, nil
+	}
+	dir, err := paths.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+// Load reads the configuration file at path, applies environment
+// overrides and validates the result. A missing file yields the defaults
+// plus overrides. Problems are reported as `Errors`, each pointing at the
+// offending line or environment variable.
+func Load(path string) (Config, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		b = nil
+	} else if err != nil {
+		return Config{}, err
+	}
+	return Parse(path, b, os.LookupEnv)
+}
+
+// Parse builds a configuration from the file content b, read from path,
+// and the environment looked up with env. An empty b means no file.
+func Parse(path string, b []byte, env func(string) (string, bool)) (Config, error) {
+	c := Default()
+	var errs Errors
+	if len(b) > 0 {
+		errs = append(errs, decodeFile(path, b, &c)...)
+	}
+	errs = append(errs, applyEnv(env, &c)...)
+	errs = append(errs, validate(path, b, c)...)
+	if len(errs) > 0 {
+		return c, errs
+	}
+	return c, nil
+}
+
+// Error is a single configuration problem. Line is 1-based and zero when
+// the problem does not come from a file line, for example an environment
+// variable.
+type Error struct {
+	Source string
+	Line   int
+	Field  string
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	loc := e.Source
+	if e.Line > 0 {
+		loc = fmt.Sprintf("%s:%d", e.Source, e.Line)
+	}
+	if e.Field == "" {
+		return fmt.Sprintf("%s: %s", loc, e.Msg)
+	}
+	return fmt.Sprintf("%s: %s: %s", loc, e.Field, e.Msg)
+}
+
+// Errors collects every problem found while loading a configuration.
+type Errors []*Error
+
+func (es Errors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// validate checks the merged configuration. Problems are attributed to
+// the file line of the offending key when the file sets it.
+func validate(path string, b []byte, c Config) Errors {
+	lines := keyLines(b)
+	var errs Errors
+	fail := func(field, format string, args ...interface{}) {
+		errs = append(errs, &Error{Source: path, Line: lines[field], Field: field, Msg: fmt.Sprintf(format, args...)})
+	}
+
+	for _, d := range []struct {
+		field string
+		v     time.Duration
+	}{
+		{"pomodoro", c.Pomodoro},
+		{"short_break", c.ShortBreak},
+		{"long_break", c.LongBreak},
+	} {
+		if d.v <= 0 {
+			fail(d.field, "must be positive, got %s", d.v)
+		} else if d.v > 24*time.Hour {
+			fail(d.field, "must be at most 24h, got %s", d.v)
+		}
+	}
+	if c.CycleLength < 1 {
+		fail("cycle_length", "must be at least 1, got %d", c.CycleLength)
+	}
+	if c.TickInterval < time.Second {
+		fail("tick_interval", "must be at least 1s, got %s", c.TickInterval)
+	}
+	if c.HTTPPort < 0 || c.HTTPPort > 65535 {
+		fail("http.port", "must be between 0 (disabled) and 65535, got %d", c.HTTPPort)
+	}
+	if c.HookTimeout <= 0 || c.HookTimeout > time.Hour {
+		fail("hooks.timeout", "must be positive and at most 1h, got %s", c.HookTimeout)
+	}
+	if c.HookConcurrency < 1 {
+		fail("hooks.concurrency", "must be at least 1, got %d", c.HookConcurrency)
+	}
+	seen := make(map[string]bool)
+	for i, w := range c.Webhooks {
+		field := fmt.Sprintf("webhooks[%d]", i)
+		if u, err := url.Parse(w.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fail(field+".url", "must be an http or https URL, got %q", w.URL)
+		} else if seen[w.URL] {
+			fail(field+".url", "duplicate webhook %s", w.URL)
+		}
+		seen[w.URL] = true
+		if w.Secret == "" {
+			fail(field+".secret", "must not be empty")
+		}
+	}
+	if !validBackend(c.NotifyBackend) {
+		fail("notifications.backend", "must be one of %s, got %q", strings.Join(notify.Backends(), ", "), c.NotifyBackend)
+	}
+	for _, m := range []struct {
+		field string
+		text  string
+	}{
+		{"notifications.pomodoro.title", c.PomodoroDone.Title},
+		{"notifications.pomodoro.body", c.PomodoroDone.Body},
+		{"notifications.break.title", c.BreakDone.Title},
+		{"notifications.break.body", c.BreakDone.Body},
+	} {
+		if _, err := template.New(m.field).Parse(m.text); err != nil {
+			fail(m.field, "invalid template: %v", err)
+		}
+	}
+	if strings.TrimSpace(c.TitleFormat) == "" {
+		fail("title_format", "must not be empty")
+	} else if _, err := template.New("title").Parse(c.TitleFormat); err != nil {
+		fail("title_format", "invalid template: %v", err)
+	}
+	return errs
+}
+
+// validBackend reports whether name is a notification backend.
+func validBackend(name string) bool {
+	for _, b := range notify.Backends() {
+		if b == name {
+			return true
+		}
+	}
+	return false
+}
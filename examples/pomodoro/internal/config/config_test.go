@@ -0,0 +1,258 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func noEnv(string) (string, bool) { return "", false }
+
+func envOf(m map[string]string) func(string) (string, bool) {
+	return func(k string) (string, bool) {
+		v, ok := m[k]
+		return v, ok
+	}
+}
+
+func TestParseFileWithComments(t *testing.T) {
+	src := `// my settings
+{
+  "pomodoro": "50m", // deep work
+  "short_break": "10m",
+  "cycle_length": 2,
+  "title_format": "{{.Minutes}} min // not a comment"
+}
+`
+	c, err := Parse("config.json", []byte(src), noEnv)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := Default()
+	want.Pomodoro = 50 * time.Minute
+	want.ShortBreak = 10 * time.Minute
+	want.CycleLength = 2
+	want.TitleFormat = "{{.Minutes}} min // not a comment"
+	if !reflect.DeepEqual(c, want) {
+		t.Fatalf("unexpected config:\n got %+v\nwant %+v", c, want)
+	}
+}
+
+func TestParseReportsLines(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			name: "invalid values",
+			src:  "{\n  \"pomodoro\": \"-5m\",\n  \"short_break\": \"soon\",\n  \"cycle_length\": 0\n}\n",
+			want: []string{
+				"config.json:3: short_break: invalid duration \"soon\"",
+				"config.json:2: pomodoro: must be positive, got -5m0s",
+				"config.json:4: cycle_length: must be at least 1, got 0",
+			},
+		},
+		{
+			name: "unknown key",
+			src:  "{\n  \"pomodoro\": \"25m\",\n  \"pomodor\": \"30m\"\n}\n",
+			want: []string{"config.json:3: pomodor: unknown setting"},
+		},
+		{
+			name: "wrong type",
+			src:  "{\n  \"cycle_length\": \"four\"\n}\n",
+			want: []string{"config.json:2: cycle_length: expected a int, got a string"},
+		},
+		{
+			name: "syntax error",
+			src:  "{\n  \"pomodoro\": \"25m\"\n  \"short_break\": \"5m\"\n}\n",
+			want: []string{"config.json:3: syntax error"},
+		},
+		{
+			name: "bad template",
+			src:  "{\n\n  \"title_format\": \"{{.Minutes\"\n}\n",
+			want: []string{"config.json:3: title_format: invalid template"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse("config.json", []byte(tt.src), noEnv)
+			var errs Errors
+			if !errors.As(err, &errs) {
+				t.Fatalf("expected Errors, got %v", err)
+			}
+			if len(errs) != len(tt.want) {
+				t.Fatalf("expected %d errors, got:\n%v", len(tt.want), err)
+			}
+			for i, w := range tt.want {
+				if !strings.HasPrefix(errs[i].Error(), w) {
+					t.Fatalf("error %d: got %q, want prefix %q", i, errs[i].Error(), w)
+				}
+			}
+		})
+	}
+}
+
+func TestEnvironmentOverridesFile(t *testing.T) {
+	src := `{"pomodoro": "50m", "tick_interval": "5s"}`
+	env := envOf(map[string]string{
+		EnvPomodoro:    "20m",
+		EnvCycleLength: "3",
+		EnvLongBreak:   "",
+	})
+	c, err := Parse("config.json", []byte(src), env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Pomodoro != 20*time.Minute || c.CycleLength != 3 || c.TickInterval != 5*time.Second || c.LongBreak != Default().LongBreak {
+		t.Fatalf("unexpected config %+v", c)
+	}
+
+	_, err = Parse("config.json", nil, envOf(map[string]string{EnvShortBreak: "0s"}))
+	if err == nil || !strings.Contains(err.Error(), "config.json: short_break: must be positive") {
+		t.Fatalf("expected validation error for env value, got %v", err)
+	}
+	_, err = Parse("config.json", nil, envOf(map[string]string{EnvCycleLength: "x"}))
+	if err == nil || !strings.HasPrefix(err.Error(), EnvCycleLength+": invalid number") {
+		t.Fatalf("expected env parse error, got %v", err)
+	}
+}
+
+func TestHooksSection(t *testing.T) {
+	src := "{\n  \"hooks\": {\n    \"timeout\": \"soon\",\n    \"concurrency\": 0\n  }\n}\n"
+	_, err := Parse("config.json", []byte(src), noEnv)
+	if err == nil || !strings.Contains(err.Error(), "config.json:3: hooks.timeout: invalid duration") {
+		t.Fatalf("expected a timeout error on line 3, got %v", err)
+	}
+	src = "{\n  \"hooks\": {\n    \"concurrency\": 0\n  }\n}\n"
+	if _, err := Parse("config.json", []byte(src), noEnv); err == nil || !strings.Contains(err.Error(), "config.json:3: hooks.concurrency: must be at least 1") {
+		t.Fatalf("expected a concurrency error on line 3, got %v", err)
+	}
+
+	c, err := Parse("config.json", []byte(`{"hooks": {"timeout": "1m"}}`), envOf(map[string]string{EnvHookConcurrency: "4"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.HookTimeout != time.Minute || c.HookConcurrency != 4 {
+		t.Fatalf("unexpected hook settings %s, %d", c.HookTimeout, c.HookConcurrency)
+	}
+}
+
+func TestWebhooksSection(t *testing.T) {
+	src := `{
+  "webhooks": [
+    {"url": "https://example.com/hook", "secret": "x"},
+    {"url": "ftp://example.com", "secret": "x"},
+    {"url": "https://example.com/hook",
+     "secret": ""}
+  ]
+}
+`
+	_, err := Parse("config.json", []byte(src), noEnv)
+	for _, want := range []string{
+		"config.json:4: webhooks[1].url: must be an http or https URL",
+		"config.json:5: webhooks[2].url: duplicate webhook https://example.com/hook",
+		"config.json:6: webhooks[2].secret: must not be empty",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q, got %v", want, err)
+		}
+	}
+	if len(Default().Webhooks) != 0 {
+		t.Fatal("expected no webhooks by default")
+	}
+}
+
+func TestNotificationsSection(t *testing.T) {
+	src := `{
+  "notifications": {
+    "backend": "growl",
+    "pomodoro": {"body": "{{.Task"},
+    "break": {"title": "{{.Kind}} done"}
+  }
+}
+`
+	_, err := Parse("config.json", []byte(src), noEnv)
+	for _, want := range []string{
+		"config.json:3: notifications.backend: must be one of auto, bell, dbus, none, notify-send, osascript",
+		"config.json:4: notifications.pomodoro.body: invalid template",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q, got %v", want, err)
+		}
+	}
+
+	c, err := Parse("config.json", []byte(`{"notifications": {"break": {"title": "{{.Kind}} done"}}}`), envOf(map[string]string{EnvNotifyBackend: "none"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.NotifyBackend != "none" {
+		t.Errorf("expected the environment to select the backend, got %q", c.NotifyBackend)
+	}
+	if c.BreakDone.Title != "{{.Kind}} done" || c.BreakDone.Body != Default().BreakDone.Body || c.PomodoroDone != Default().PomodoroDone {
+		t.Errorf("expected only the break title to change, got %+v and %+v", c.PomodoroDone, c.BreakDone)
+	}
+}
+
+func TestHTTPSection(t *testing.T) {
+	src := "{\n  \"http\": {\n    \"port\": 70000\n  }\n}\n"
+	_, err := Parse("config.json", []byte(src), noEnv)
+	if err == nil || !strings.Contains(err.Error(), "config.json:3: http.port: must be between") {
+		t.Fatalf("expected a port error on line 3, got %v", err)
+	}
+
+	c, err := Parse("config.json", []byte(`{"http": {"port": 7411}}`), envOf(map[string]string{EnvHTTPPort: "7412"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.HTTPPort != 7412 {
+		t.Fatalf("expected the environment to override the port, got %d", c.HTTPPort)
+	}
+	if Default().HTTPPort != 0 {
+		t.Fatal("expected the HTTP API to be disabled by default")
+	}
+}
+
+func TestFormatRoundTrips(t *testing.T) {
+	c := Default()
+	c.Pomodoro = 45 * time.Minute
+	c.TitleFormat = `{{.Minutes}}m "{{.Cycle}}"`
+	c.HTTPPort = 7411
+	c.HookTimeout = 30 * time.Second
+	c.HookConcurrency = 1
+	c.Webhooks = []Webhook{{URL: "https://example.com/a", Secret: "x"}, {URL: "http://localhost:9000/b", Secret: `q"uote`}}
+	c.NotifyBackend = "bell"
+	c.PomodoroDone = Message{Title: "Done <{{.Task}}>", Body: "{{.Minutes}}m & {{.Cycle}}"}
+
+	got, err := Parse("printed", Format(c), noEnv)
+	if err != nil {
+		t.Fatalf("printed config does not load: %v\n%s", err, Format(c))
+	}
+	if !reflect.DeepEqual(got, c) {
+		t.Fatalf("round trip mismatch:\n got %+v\nwant %+v", got, c)
+	}
+}
+
+func TestLoadMissingFileAndPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	c, err := Load(path)
+	if err != nil || !reflect.DeepEqual(c, Default()) {
+		t.Fatalf("expected defaults for missing file, got %+v, %v", c, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"long_break": "30m"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := Load(path); err != nil || c.LongBreak != 30*time.Minute {
+		t.Fatalf("unexpected load result %+v, %v", c, err)
+	}
+
+	t.Setenv(PathEnv, path)
+	if p, err := Path(); err != nil || p != path {
+		t.Fatalf("expected %s from %s, got %s, %v", path, PathEnv, p, err)
+	}
+}
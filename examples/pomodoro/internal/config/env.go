@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Environment variables overriding individual settings.
+const (
+	EnvPomodoro     = "POMODORO_POMODORO"
+	EnvShortBreak   = "POMODORO_SHORT_BREAK"
+	EnvLongBreak    = "POMODORO_LONG_BREAK"
+	EnvCycleLength  = "POMODORO_CYCLE_LENGTH"
+	EnvTitleFormat  = "POMODORO_TITLE_FORMAT"
+	EnvTickInterval = "POMODORO_TICK_INTERVAL"
+	EnvHTTPPort     = "POMODORO_HTTP_PORT"
+
+	EnvHookTimeout     = "POMODORO_HOOK_TIMEOUT"
+	EnvHookConcurrency = "POMODORO_HOOK_CONCURRENCY"
+
+	EnvNotifyBackend = "POMODORO_NOTIFY_BACKEND"
+)
+
+// applyEnv overrides settings of c from the environment looked up with
+// env. Empty variables are ignored.
+func applyEnv(env func(string) (string, bool), c *Config) Errors {
+	var errs Errors
+	lookup := func(name string) (string, bool) {
+		v, ok := env(name)
+		return v, ok && v != ""
+	}
+	duration := func(name string, dst *time.Duration) {
+		v, ok := lookup(name)
+		if !ok {
+			return
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			errs = append(errs, &Error{Source: name, Msg: fmt.Sprintf("invalid duration %q", v)})
+			return
+		}
+		*dst = d
+	}
+
+	duration(EnvPomodoro, &c.Pomodoro)
+	duration(EnvShortBreak, &c.ShortBreak)
+	duration(EnvLongBreak, &c.LongBreak)
+	duration(EnvTickInterval, &c.TickInterval)
+	duration(EnvHookTimeout, &c.HookTimeout)
+	number := func(name string, dst *int) {
+		v, ok := lookup(name)
+		if !ok {
+			return
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			errs = append(errs, &Error{Source: name, Msg: fmt.Sprintf("invalid number %q", v)})
+			return
+		}
+		*dst = n
+	}
+	number(EnvCycleLength, &c.CycleLength)
+	number(EnvHTTPPort, &c.HTTPPort)
+	number(EnvHookConcurrency, &c.HookConcurrency)
+	if v, ok := lookup(EnvTitleFormat); ok {
+		c.TitleFormat = v
+	}
+	if v, ok := lookup(EnvNotifyBackend); ok {
+		c.NotifyBackend = v
+	}
+	return errs
+}
@@ -0,0 +1,228 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// fileConfig mirrors the keys accepted in the configuration file. Pointer
+// fields tell unset keys apart from zero values.
+type fileConfig struct {
+	Pomodoro     *string `json:"pomodoro"`
+	ShortBreak   *string `json:"short_break"`
+	LongBreak    *string `json:"long_break"`
+	CycleLength  *int    `json:"cycle_length"`
+	TitleFormat  *string `json:"title_format"`
+	TickInterval *string `json:"tick_interval"`
+	HTTP         *struct {
+		Port *int `json:"port"`
+	} `json:"http"`
+	Hooks *struct {
+		Timeout     *string `json:"timeout"`
+		Concurrency *int    `json:"concurrency"`
+	} `json:"hooks"`
+	Webhooks []struct {
+		URL    string `json:"url"`
+		Secret string `json:"secret"`
+	} `json:"webhooks"`
+	Notifications *struct {
+		Backend  *string      `json:"backend"`
+		Pomodoro *fileMessage `json:"pomodoro"`
+		Break    *fileMessage `json:"break"`
+	} `json:"notifications"`
+}
+
+// fileMessage is the text of a notification in the configuration file.
+type fileMessage struct {
+	Title *string `json:"title"`
+	Body  *string `json:"body"`
+}
+
+// apply sets the fields of m given in the file.
+func (f *fileMessage) apply(m *Message) {
+	if f == nil {
+		return
+	}
+	if f.Title != nil {
+		m.Title = *f.Title
+	}
+	if f.Body != nil {
+		m.Body = *f.Body
+	}
+}
+
+// decodeFile merges the file content b into c.
+func decodeFile(path string, b []byte, c *Config) Errors {
+	src := stripComments(b)
+	lines := keyLines(b)
+
+	var f fileConfig
+	dec := json.NewDecoder(bytes.NewReader(src))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&f); err != nil {
+		return Errors{decodeError(path, src, lines, err)}
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return Errors{{Source: path, Line: lineAt(src, dec.InputOffset()), Msg: "unexpected content after the configuration object"}}
+	}
+
+	var errs Errors
+	duration := func(field string, v *string, dst *time.Duration) {
+		if v == nil {
+			return
+		}
+		d, err := time.ParseDuration(*v)
+		if err != nil {
+			errs = append(errs, &Error{Source: path, Line: lines[field], Field: field, Msg: fmt.Sprintf("invalid duration %q (use values like \"25m\" or \"90s\")", *v)})
+			return
+		}
+		*dst = d
+	}
+	duration("pomodoro", f.Pomodoro, &c.Pomodoro)
+	duration("short_break", f.ShortBreak, &c.ShortBreak)
+	duration("long_break", f.LongBreak, &c.LongBreak)
+	duration("tick_interval", f.TickInterval, &c.TickInterval)
+	if f.Hooks != nil {
+		duration("hooks.timeout", f.Hooks.Timeout, &c.HookTimeout)
+		if f.Hooks.Concurrency != nil {
+			c.HookConcurrency = *f.Hooks.Concurrency
+		}
+	}
+	if f.Webhooks != nil {
+		c.Webhooks = make([]Webhook, len(f.Webhooks))
+		for i, w := range f.Webhooks {
+			c.Webhooks[i] = Webhook{URL: w.URL, Secret: w.Secret}
+		}
+	}
+	if n := f.Notifications; n != nil {
+		if n.Backend != nil {
+			c.NotifyBackend = *n.Backend
+		}
+		n.Pomodoro.apply(&c.PomodoroDone)
+		n.Break.apply(&c.BreakDone)
+	}
+	if f.CycleLength != nil {
+		c.CycleLength = *f.CycleLength
+	}
+	if f.TitleFormat != nil {
+		c.TitleFormat = *f.TitleFormat
+	}
+	if f.HTTP != nil && f.HTTP.Port != nil {
+		c.HTTPPort = *f.HTTP.Port
+	}
+	return errs
+}
+
+// decodeError turns a JSON decoding error into an Error with the line it
+// refers to.
+func decodeError(path string, src []byte, lines map[string]int, err error) *Error {
+	var syn *json.SyntaxError
+	var typ *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syn):
+		return &Error{Source: path, Line: lineAt(src, syn.Offset), Msg: "syntax error: " + syn.Error()}
+	case errors.As(err, &typ):
+		return &Error{Source: path, Line: lineAt(src, typ.Offset), Field: typ.Field, Msg: fmt.Sprintf("expected a %s, got a %s", typ.Type, typ.Value)}
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return &Error{Source: path, Line: lineAt(src, int64(len(src))), Msg: "unexpected end of file"}
+	}
+	const unknown = "json: unknown field "
+	if msg := err.Error(); strings.HasPrefix(msg, unknown) {
+		field := strings.Trim(strings.TrimPrefix(msg, unknown), `"`)
+		return &Error{Source: path, Line: lines[field], Field: field, Msg: "unknown setting"}
+	}
+	return &Error{Source: path, Msg: err.Error()}
+}
+
+// stripComments blanks out `//` comments outside of JSON strings. Comment
+// bytes are replaced by spaces so offsets and line numbers are preserved.
+func stripComments(b []byte) []byte {
+	out := append([]byte(nil), b...)
+	inString, escaped := false, false
+	for i := 0; i < len(out); i++ {
+		ch := out[i]
+		switch {
+		case inString:
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			}
+		case ch == '"':
+			inString = true
+		case ch == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		}
+	}
+	return out
+}
+
+// keyLines maps the keys of the file's top-level object to the line they
+// appear on. Nested keys are recorded with dotted paths such as
+// "hooks.timeout". It returns what it found before any syntax error.
+func keyLines(b []byte) map[string]int {
+	lines := make(map[string]int)
+	if len(b) == 0 {
+		return lines
+	}
+	src := stripComments(b)
+	dec := json.NewDecoder(bytes.NewReader(src))
+	walkKeys(dec, src, "", lines)
+	return lines
+}
+
+// walkKeys reads one JSON value from dec, recording object keys below
+// prefix. It reports false when decoding failed.
+func walkKeys(dec *json.Decoder, src []byte, prefix string, lines map[string]int) bool {
+	tok, err := dec.Token()
+	if err != nil {
+		return false
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return true
+	}
+	switch delim {
+	case '{':
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return false
+			}
+			key, _ := tok.(string)
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			lines[key] = lineAt(src, dec.InputOffset())
+			if !walkKeys(dec, src, key, lines) {
+				return false
+			}
+		}
+	case '[':
+		for i := 0; dec.More(); i++ {
+			if !walkKeys(dec, src, fmt.Sprintf("%s[%d]", prefix, i), lines) {
+				return false
+			}
+		}
+	}
+	_, err = dec.Token() // closing delimiter
+	return err == nil
+}
+
+// lineAt returns the 1-based line number of the byte at offset.
+func lineAt(b []byte, offset int64) int {
+	if offset > int64(len(b)) {
+		offset = int64(len(b))
+	}
+	return bytes.Count(b[:offset], []byte("\n")) + 1
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/notify"
+)
+
+// Format renders c as a documented configuration file that `Load` accepts.
+func Format(c Config) []byte {
+	var b bytes.Buffer
+	str := func(s string) string {
+		q, _ := json.Marshal(s)
+		return string(q)
+	}
+	dur := func(d time.Duration) string { return str(d.String()) }
+
+	fmt.Fprintf(&b, "// Pomodoro configuration. Durations use Go syntax such as \"25m\" or \"90s\".\n")
+	fmt.Fprintf(&b, "// Each setting can be overridden with the environment variable named after it.\n")
+	fmt.Fprintf(&b, "{\n")
+	fmt.Fprintf(&b, "  // Length of a pomodoro (%s).\n", EnvPomodoro)
+	fmt.Fprintf(&b, "  \"pomodoro\": %s,\n", dur(c.Pomodoro))
+	fmt.Fprintf(&b, "  // Length of a short break (%s).\n", EnvShortBreak)
+	fmt.Fprintf(&b, "  \"short_break\": %s,\n", dur(c.ShortBreak))
+	fmt.Fprintf(&b, "  // Length of a long break (%s).\n", EnvLongBreak)
+	fmt.Fprintf(&b, "  \"long_break\": %s,\n", dur(c.LongBreak))
+	fmt.Fprintf(&b, "  // Pomodoros before the next break is a long one (%s).\n", EnvCycleLength)
+	fmt.Fprintf(&b, "  \"cycle_length\": %d,\n", c.CycleLength)
+	fmt.Fprintf(&b, "  // Tray title while a session runs, as a Go template (%s).\n", EnvTitleFormat)
+	fmt.Fprintf(&b, "  // Fields: .Minutes, .Countdown, .Remaining, .State, .Kind, .Task, .Cycle.\n")
+	fmt.Fprintf(&b, "  \"title_format\": %s,\n", str(c.TitleFormat))
+	fmt.Fprintf(&b, "  // How often the tray title is refreshed (%s).\n", EnvTickInterval)
+	fmt.Fprintf(&b, "  \"tick_interval\": %s,\n", dur(c.TickInterval))
+	fmt.Fprintf(&b, "  // HTTP control API on 127.0.0.1. Port 0 disables it (%s).\n", EnvHTTPPort)
+	fmt.Fprintf(&b, "  // Requests need the bearer token stored in the http-token file.\n")
+	fmt.Fprintf(&b, "  \"http\": {\n")
+	fmt.Fprintf(&b, "    \"port\": %d\n", c.HTTPPort)
+	fmt.Fprintf(&b, "  },\n")
+	fmt.Fprintf(&b, "  // Scripts in the hooks.d directory run on state changes, each for at\n")
+	fmt.Fprintf(&b, "  // most the timeout (%s) and at most concurrency at a time (%s).\n", EnvHookTimeout, EnvHookConcurrency)
+	fmt.Fprintf(&b, "  \"hooks\": {\n")
+	fmt.Fprintf(&b, "    \"timeout\": %s,\n", dur(c.HookTimeout))
+	fmt.Fprintf(&b, "    \"concurrency\": %d\n", c.HookConcurrency)
+	fmt.Fprintf(&b, "  },\n")
+	fmt.Fprintf(&b, "  // Notifications when a session completes. The backend is one of\n")
+	fmt.Fprintf(&b, "  // %s (%s).\n", strings.Join(notify.Backends(), ", "), EnvNotifyBackend)
+	fmt.Fprintf(&b, "  // Title and body are Go templates; fields: .Kind, .Task, .Minutes, .Cycle.\n")
+	fmt.Fprintf(&b, "  \"notifications\": {\n")
+	fmt.Fprintf(&b, "    \"backend\": %s,\n", str(c.NotifyBackend))
+	fmt.Fprintf(&b, "    \"pomodoro\": {\"title\": %s, \"body\": %s},\n", str(c.PomodoroDone.Title), str(c.PomodoroDone.Body))
+	fmt.Fprintf(&b, "    \"break\": {\"title\": %s, \"body\": %s}\n", str(c.BreakDone.Title), str(c.BreakDone.Body))
+	fmt.Fprintf(&b, "  },\n")
+	fmt.Fprintf(&b, "  // Endpoints receiving every state change as JSON, signed with the\n")
+	fmt.Fprintf(&b, "  // secret, for example {\"url\": \"https://example.com/hook\", \"secret\": \"...\"}.\n")
+	if len(c.Webhooks) == 0 {
+		fmt.Fprintf(&b, "  \"webhooks\": []\n")
+	} else {
+		fmt.Fprintf(&b, "  \"webhooks\": [\n")
+		for i, w := range c.Webhooks {
+			sep := ","
+			if i == len(c.Webhooks)-1 {
+				sep = ""
+			}
+			fmt.Fprintf(&b, "    {\"url\": %s, \"secret\": %s}%s\n", str(w.URL), str(w.Secret), sep)
+		}
+		fmt.Fprintf(&b, "  ]\n")
+	}
+	fmt.Fprintf(&b, "}\n")
+	return b.Bytes()
+}
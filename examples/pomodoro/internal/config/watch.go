@@ -0,0 +1,99 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/clock"
+)
+
+// DefaultPollInterval is how often a Watcher checks the file by default.
+const DefaultPollInterval = 2 * time.Second
+
+// Watcher reloads a configuration file when its modification time or size
+// changes. It polls instead of relying on file system notifications to
+// stay dependency-free and portable.
+type Watcher struct {
+	path     string
+	clock    clock.Clock
+	interval time.Duration
+	apply    func(Config)
+	reject   func(error)
+
+	// stamp identifies the file version last seen; exists is false while
+	// the file is missing.
+	stamp  time.Time
+	size   int64
+	exists bool
+	// statErr is the message of the error the last check of the file
+	// failed with, reported once rather than on every poll.
+	statErr string
+}
+
+// NewWatcher returns a Watcher for the file at path that checks it every
+// interval on c. Valid new configurations are passed to apply; load or
+// validation errors are passed to reject and the previous configuration
+// stays in effect. The current file version is taken as the baseline, so
+// only later changes are reported.
+func NewWatcher(path string, c clock.Clock, interval time.Duration, apply func(Config), reject func(error)) *Watcher {
+	w := &Watcher{path: path, clock: c, interval: interval, apply: apply, reject: reject}
+	w.stamp, w.size, w.exists, _ = stat(path)
+	return w
+}
+
+// Run polls the file until ctx is done.
+func (w *Watcher) Run(ctx context.Context) {
+	tk := w.clock.NewTicker(w.interval)
+	defer tk.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tk.C():
+			w.Poll()
+		}
+	}
+}
+
+// Poll checks the file once and reloads it if it changed. It reports
+// whether a change was detected. Removing the file reverts to the
+// defaults plus environment overrides. A file that cannot be checked is
+// reported to reject once until the error changes.
+func (w *Watcher) Poll() bool {
+	stamp, size, exists, err := stat(w.path)
+	if err != nil {
+		if err.Error() != w.statErr {
+			w.statErr = err.Error()
+			w.reject(err)
+		}
+		return false
+	}
+	w.statErr = ""
+	if exists == w.exists && size == w.size && stamp.Equal(w.stamp) {
+		return false
+	}
+	w.stamp, w.size, w.exists = stamp, size, exists
+
+	c, err := Load(w.path)
+	if err != nil {
+		w.reject(err)
+		return true
+	}
+	w.apply(c)
+	return true
+}
+
+// stat returns the modification time and size of the file at path and
+// whether it exists.
+func stat(path string) (time.Time, int64, bool, error) {
+	fi, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return time.Time{}, 0, false, nil
+	}
+	if err != nil {
+		return time.Time{}, 0, false, err
+	}
+	return fi.ModTime(), fi.Size(), true, nil
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/clock"
+)
+
+// writeAt writes content to path and sets its modification time, so
+// changes are detected regardless of file system timestamp resolution.
+func writeAt(t *testing.T, path, content string, mtime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatcherPollAppliesAndRejects(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	base := time.Date(2025, 12, 5, 9, 0, 0, 0, time.UTC)
+	writeAt(t, path, `{"pomodoro": "25m"}`, base)
+
+	var applied []Config
+	var rejected []error
+	w := NewWatcher(path, clock.NewFake(base), time.Second,
+		func(c Config) { applied = append(applied, c) },
+		func(err error) { rejected = append(rejected, err) })
+
+	if w.Poll() {
+		t.Fatal("unchanged file reported as changed")
+	}
+
+	writeAt(t, path, `{"pomodoro": "50m"}`, base.Add(time.Minute))
+	if !w.Poll() || len(applied) != 1 || applied[0].Pomodoro != 50*time.Minute {
+		t.Fatalf("expected reload with 50m pomodoro, got %+v", applied)
+	}
+
+	writeAt(t, path, `{"pomodoro": "-1m"}`, base.Add(2*time.Minute))
+	if !w.Poll() || len(rejected) != 1 || len(applied) != 1 {
+		t.Fatalf("expected invalid reload to be rejected, applied=%d rejected=%v", len(applied), rejected)
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if !w.Poll() || len(applied) != 2 || !reflect.DeepEqual(applied[1], Default()) {
+		t.Fatalf("expected defaults after removal, got %+v", applied)
+	}
+}
+
+func TestWatcherReportsStatErrorsOnce(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("a path below a file does not exist on Windows rather than failing")
+	}
+	dir := t.TempDir()
+	// a path below a regular file cannot be checked, even by root
+	blocker := filepath.Join(dir, "blocker")
+	path := filepath.Join(blocker, "config.json")
+	if err := os.WriteFile(blocker, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var rejected []error
+	w := NewWatcher(path, clock.NewFake(time.Date(2025, 12, 5, 9, 0, 0, 0, time.UTC)), time.Second,
+		func(c Config) { t.Errorf("unexpected apply %+v", c) },
+		func(err error) { rejected = append(rejected, err) })
+
+	for i := 0; i < 3; i++ {
+		if w.Poll() {
+			t.Fatal("unreadable file reported as changed")
+		}
+	}
+	if len(rejected) != 1 {
+		t.Fatalf("expected the error to be reported once, got %v", rejected)
+	}
+
+	// the error clears once the path can be checked again, and is
+	// reported anew when it comes back
+	if err := os.Remove(blocker); err != nil {
+		t.Fatal(err)
+	}
+	w.Poll()
+	if err := os.WriteFile(blocker, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	w.Poll()
+	w.Poll()
+	if len(rejected) != 2 {
+		t.Fatalf("expected the returning error to be reported again, got %v", rejected)
+	}
+}
+
+func TestWatcherRunPollsOnTicks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	base := time.Date(2025, 12, 5, 9, 0, 0, 0, time.UTC)
+	c := clock.NewFake(base)
+
+	applied := make(chan Config, 1)
+	w := NewWatcher(path, c, DefaultPollInterval,
+		func(cfg Config) { applied <- cfg },
+		func(err error) { t.Errorf("unexpected reject: %v", err) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+
+	// wait for Run to register its ticker
+	for i := 0; c.Pending() == 0; i++ {
+		if i > 100 {
+			t.Fatal("watcher did not start its ticker")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	writeAt(t, path, `{"cycle_length": 6}`, base)
+	c.Advance(DefaultPollInterval)
+	select {
+	case cfg := <-applied:
+		if cfg.CycleLength != 6 {
+			t.Fatalf("unexpected config %+v", cfg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for reload")
+	}
+}
@@ -0,0 +1,156 @@
+package control
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/status"
+)
+
+// dialTimeout bounds connecting to the socket.
+const dialTimeout = 2 * time.Second
+
+// ErrNotRunning is returned by Dial when no instance listens on the
+// socket.
+var ErrNotRunning = errors.New("no running pomodoro instance")
+
+// Client sends requests to a running app.
+type Client struct {
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+	nextID int64
+}
+
+// Dial connects to the control socket at path. It returns an error
+// wrapping `ErrNotRunning` when nothing listens there.
+func Dial(path string) (*Client, error) {
+	c, err := net.DialTimeout("unix", path, dialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("%w (%s): %v", ErrNotRunning, path, err)
+	}
+	return &Client{conn: c, reader: bufio.NewReader(c)}, nil
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Call invokes method with params and decodes the result into result.
+// Server-side failures are returned as *Error.
+func (c *Client) Call(method string, params, result interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextID++
+	req := request{JSONRPC: "2.0", ID: json.RawMessage(strconv.FormatInt(c.nextID, 10)), Method: method}
+	if params != nil {
+		b, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		req.Params = b
+	}
+	b, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	if _, err := c.conn.Write(append(b, '\n')); err != nil {
+		return err
+	}
+
+	line, err := c.reader.ReadBytes('\n')
+	if err != nil {
+		return fmt.Errorf("control: reading response: %w", err)
+	}
+	var resp response
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return fmt.Errorf("control: invalid response: %w", err)
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if !bytes.Equal(resp.ID, req.ID) {
+		return fmt.Errorf("control: response id %s does not match request %s", resp.ID, req.ID)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(resp.Result, result)
+}
+
+// Status returns the current status.
+func (c *Client) Status() (status.Status, error) {
+	return c.call(MethodStatus, nil)
+}
+
+// Start starts a session of kind, see `StartParams`.
+func (c *Client) Start(kind, task string) (status.Status, error) {
+	return c.call(MethodStart, StartParams{Kind: kind, Task: task})
+}
+
+// Pause pauses the running session.
+func (c *Client) Pause() (status.Status, error) {
+	return c.call(MethodPause, nil)
+}
+
+// Resume resumes the paused session.
+func (c *Client) Resume() (status.Status, error) {
+	return c.call(MethodResume, nil)
+}
+
+// Stop abandons the active session without counting it.
+func (c *Client) Stop() (status.Status, error) {
+	return c.call(MethodStop, nil)
+}
+
+// Watch asks the app to stream its status. It calls fn with the current
+// status and then on every state change and every second until fn
+// returns an error, which Watch returns, or the connection fails. The
+// client cannot be used for other calls afterwards.
+func (c *Client) Watch(fn func(status.Status) error) error {
+	var st status.Status
+	if err := c.Call(MethodWatch, nil, &st); err != nil {
+		return err
+	}
+	if err := fn(st); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for {
+		line, err := c.reader.ReadBytes('\n')
+		if err != nil {
+			return fmt.Errorf("control: watch: %w", err)
+		}
+		var n notification
+		if err := json.Unmarshal(line, &n); err != nil {
+			return fmt.Errorf("control: invalid notification: %w", err)
+		}
+		if n.Method != MethodStatus {
+			continue
+		}
+		st = status.Status{}
+		if err := json.Unmarshal(n.Params, &st); err != nil {
+			return fmt.Errorf("control: invalid notification: %w", err)
+		}
+		if err := fn(st); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) call(method string, params interface{}) (status.Status, error) {
+	var s status.Status
+	err := c.Call(method, params, &s)
+	return s, err
+}
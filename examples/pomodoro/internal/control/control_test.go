@@ -0,0 +1,257 @@
+package control
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+	"github.com/co0p/4dc/examples/pomodoro/internal/apptest"
+	"github.com/co0p/4dc/examples/pomodoro/internal/clock"
+	"github.com/co0p/4dc/examples/pomodoro/internal/status"
+)
+
+// serve starts a server for a fresh app on a socket in a temporary
+// directory and returns the app and the socket path.
+func serve(t *testing.T) (app.App, *clock.Fake, string) {
+	t.Helper()
+	a, c := apptest.NewApp(t)
+	// keep the path short: Unix socket paths are limited to about 100 bytes
+	dir, err := os.MkdirTemp("", "pomo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "s.sock")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- NewServer(a).ListenAndServe(ctx, path) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("serve: %v", err)
+		}
+	})
+
+	apptest.Eventually(t, "the control socket", func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	})
+	return a, c, path
+}
+
+func dial(t *testing.T, path string) *Client {
+	t.Helper()
+	cl, err := Dial(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cl.Close() })
+	return cl
+}
+
+func TestClientDrivesApp(t *testing.T) {
+	a, c, path := serve(t)
+	cl := dial(t, path)
+
+	st, err := cl.Start("pomodoro", "ABC-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.State != app.StatePomodoroRunning || st.Task != "ABC-1" {
+		t.Fatalf("unexpected status %+v", st)
+	}
+
+	c.Advance(10 * time.Minute)
+	if st, _ = cl.Status(); st.RemainingSeconds != 15*60 {
+		t.Fatalf("expected 15m left, got %+v", st)
+	}
+	if st, _ = cl.Pause(); st.State != app.StatePaused {
+		t.Fatalf("expected paused, got %+v", st)
+	}
+	if st, _ = cl.Resume(); st.State != app.StatePomodoroRunning {
+		t.Fatalf("expected running, got %+v", st)
+	}
+	if st, _ = cl.Stop(); st.State != app.StateIdle {
+		t.Fatalf("expected idle, got %+v", st)
+	}
+	if st, _ = cl.Start("break", ""); st.Kind != app.KindShortBreak {
+		t.Fatalf("expected a short break, got %+v", st)
+	}
+	if a.State() != app.StateBreakRunning {
+		t.Fatalf("expected the app to run a break, got %s", a.State())
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	_, _, path := serve(t)
+	cl := dial(t, path)
+
+	var rpcErr *Error
+	if _, err := cl.Start("nap", ""); !errors.As(err, &rpcErr) || rpcErr.Code != CodeInvalidParams {
+		t.Fatalf("expected invalid params, got %v", err)
+	}
+	if err := cl.Call("explode", nil, nil); !errors.As(err, &rpcErr) || rpcErr.Code != CodeMethodNotFound {
+		t.Fatalf("expected method not found, got %v", err)
+	}
+
+	// malformed lines get an error response and keep the connection open
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	raw := &Client{conn: conn, reader: bufio.NewReader(conn)}
+	if _, err := conn.Write([]byte("not json\n")); err != nil {
+		t.Fatal(err)
+	}
+	line, err := raw.reader.ReadString('\n')
+	if err != nil || !strings.Contains(line, `"code":-32700`) {
+		t.Fatalf("expected a parse error response, got %q, %v", line, err)
+	}
+	if _, err := raw.Status(); err != nil {
+		t.Fatalf("expected the connection to stay usable, got %v", err)
+	}
+}
+
+func TestRequestIDs(t *testing.T) {
+	a, _, path := serve(t)
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	send := func(line string) {
+		t.Helper()
+		if _, err := conn.Write([]byte(line + "\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expect := func(id string) {
+		t.Helper()
+		line, err := r.ReadString('\n')
+		if err != nil || !strings.HasPrefix(line, `{"jsonrpc":"2.0","id":`+id+`,`) {
+			t.Fatalf("expected a response with id %s, got %q, %v", id, line, err)
+		}
+	}
+
+	send(`{"jsonrpc":"2.0","id":"abc-1","method":"status"}`)
+	expect(`"abc-1"`)
+	send(`{"jsonrpc":"2.0","id":7.50,"method":"status"}`)
+	expect(`7.50`)
+	send(`{"jsonrpc":"2.0","id":{"n":1},"method":"status"}`)
+	expect(`null`)
+
+	// notifications are carried out without a response, failed ones too
+	send(`{"jsonrpc":"2.0","method":"start","params":{"kind":"pomodoro"}}`)
+	send(`{"jsonrpc":"2.0","method":"explode"}`)
+	send(`{"jsonrpc":"2.0","id":2,"method":"status"}`)
+	expect(`2`)
+	if a.State() != app.StatePomodoroRunning {
+		t.Fatalf("expected the notification to start a pomodoro, got %s", a.State())
+	}
+}
+
+func TestDialWithoutInstance(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "none.sock")
+	if _, err := Dial(path); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("expected ErrNotRunning, got %v", err)
+	}
+}
+
+func TestListenRefusesLiveSocketAndReplacesStaleOne(t *testing.T) {
+	_, _, path := serve(t)
+
+	a, _ := app.NewWithOptions()
+	if err := NewServer(a).ListenAndServe(context.Background(), path); err == nil {
+		t.Fatal("expected a second server on a live socket to fail")
+	}
+
+	// a plain file is what a crashed process leaves behind
+	stale := filepath.Join(filepath.Dir(path), "stale.sock")
+	if err := os.WriteFile(stale, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- NewServer(a).ListenAndServe(ctx, stale) }()
+	var cl *Client
+	for i := 0; i < 100; i++ {
+		if c, err := Dial(stale); err == nil {
+			cl = c
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if cl == nil {
+		t.Fatal("expected the stale socket to be replaced")
+	}
+	cl.Close()
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatchStreamsStateChanges(t *testing.T) {
+	a, _, path := serve(t)
+	cl := dial(t, path)
+
+	updates := make(chan status.Status, 16)
+	done := make(chan error, 1)
+	go func() {
+		done <- cl.Watch(func(st status.Status) error {
+			updates <- st
+			return nil
+		})
+	}()
+
+	next := func(want app.State) {
+		t.Helper()
+		timeout := time.After(time.Second)
+		for {
+			select {
+			case st := <-updates:
+				// periodic updates repeat the previous state
+				if st.State == want {
+					return
+				}
+			case <-timeout:
+				t.Fatalf("no update with state %s", want)
+			}
+		}
+	}
+	next(app.StateIdle)
+	a.StartPomodoroFor("ABC-1")
+	next(app.StatePomodoroRunning)
+	a.Pause()
+	next(app.StatePaused)
+
+	cl.Close()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("expected Watch to fail once the connection is closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Watch did not return after Close")
+	}
+}
+
+func TestWatchStopsWhenCallbackFails(t *testing.T) {
+	_, _, path := serve(t)
+	cl := dial(t, path)
+
+	stop := errors.New("enough")
+	if err := cl.Watch(func(status.Status) error { return stop }); err != stop {
+		t.Fatalf("Watch returned %v, want the callback error", err)
+	}
+}
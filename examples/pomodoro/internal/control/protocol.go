@@ -0,0 +1,86 @@
+// Package control lets other processes drive the running app over a Unix
+// domain socket. The protocol is line-delimited JSON-RPC 2.0: every
+// request and every response is one JSON object on its own line.
+//
+// The id of a request, a string or a number, is echoed back unchanged in
+// its response. A request without an id is a notification: the server
+// carries it out but does not answer.
+//
+// Methods:
+//
+//	status                    -> status.Status
+//	start  {"kind", "task"}   -> status.Status
+//	pause, resume, stop       -> status.Status
+//	watch                     -> status.Status, then notifications
+//
+// After watch the server sends a "status" notification carrying a
+// status.Status on every state change and every second until the client
+// closes the connection.
+//
+// The kind of start is "pomodoro", "short-break", "long-break" or "break"
+// for the next break of the cycle; only a pomodoro accepts a task.
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Method names understood by the server.
+const (
+	MethodStatus = "status"
+	MethodStart  = "start"
+	MethodPause  = "pause"
+	MethodResume = "resume"
+	MethodStop   = "stop"
+	MethodWatch  = "watch"
+)
+
+// JSON-RPC error codes used by the server.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+)
+
+// StartParams are the parameters of the start method.
+type StartParams struct {
+	Kind string `json:"kind"`
+	Task string `json:"task,omitempty"`
+}
+
+// request is a JSON-RPC request. ID is nil for notifications.
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response is a JSON-RPC response; exactly one of Result and Error is
+// set. A nil ID is sent as null, for requests whose id is unknown.
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// notification is a JSON-RPC notification sent by the server while a
+// client watches.
+type notification struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+// Error is a JSON-RPC error returned by the server.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("control: %s (code %d)", e.Message, e.Code)
+}
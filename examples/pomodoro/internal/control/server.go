@@ -0,0 +1,233 @@
+package control
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+	"github.com/co0p/4dc/examples/pomodoro/internal/status"
+)
+
+// maxLine bounds the size of a single request line.
+const maxLine = 64 << 10
+
+// watchInterval is the cadence of status notifications between state
+// changes while a client watches.
+const watchInterval = time.Second
+
+// Server answers control requests for an app.
+type Server struct {
+	app           app.App
+	watchInterval time.Duration
+}
+
+// NewServer returns a server controlling a.
+func NewServer(a app.App) *Server {
+	return &Server{app: a, watchInterval: watchInterval}
+}
+
+// ListenAndServe listens on the Unix socket at path and serves until ctx
+// is done. A socket file left behind by a process that is no longer
+// running is replaced; a socket another process still answers on is an
+// error. The socket is removed on return.
+func (s *Server) ListenAndServe(ctx context.Context, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	if c, err := net.Dial("unix", path); err == nil {
+		c.Close()
+		return fmt.Errorf("control: %s is in use by another instance", path)
+	}
+	// the dial failed, so any file at path is stale
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		ln.Close()
+		return err
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve accepts connections on ln until ctx is done, then closes ln and
+// all open connections.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	var (
+		mu    sync.Mutex
+		conns = make(map[net.Conn]bool)
+		wg    sync.WaitGroup
+	)
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+		mu.Lock()
+		for c := range conns {
+			c.Close()
+		}
+		mu.Unlock()
+	}()
+
+	for {
+		c, err := ln.Accept()
+		if err != nil {
+			wg.Wait()
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		mu.Lock()
+		conns[c] = true
+		mu.Unlock()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.serveConn(c)
+			mu.Lock()
+			delete(conns, c)
+			mu.Unlock()
+		}()
+	}
+}
+
+// serveConn answers requests on c, one per line, until c is closed.
+func (s *Server) serveConn(c net.Conn) {
+	defer c.Close()
+	sc := bufio.NewScanner(c)
+	sc.Buffer(make([]byte, 0, 4096), maxLine)
+	enc := json.NewEncoder(c)
+	for sc.Scan() {
+		resp, reply, watch := s.handle(sc.Bytes())
+		if reply {
+			if err := enc.Encode(resp); err != nil {
+				return
+			}
+		}
+		if watch {
+			s.watch(sc, enc)
+			return
+		}
+	}
+}
+
+// watch sends status notifications to a watching client on every state
+// change and every watch interval until the client hangs up or a write
+// fails.
+func (s *Server) watch(sc *bufio.Scanner, enc *json.Encoder) {
+	changed := make(chan struct{}, 1)
+	unsubscribe := s.app.SubscribeStateChange(func(app.State) {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+	defer unsubscribe()
+
+	// a watching client sends nothing more: the read ends when it hangs up
+	hungUp := make(chan struct{})
+	go func() {
+		for sc.Scan() {
+		}
+		close(hungUp)
+	}()
+
+	ticker := time.NewTicker(s.watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-hungUp:
+			return
+		case <-changed:
+		case <-ticker.C:
+		}
+		params, err := json.Marshal(status.Of(s.app))
+		if err != nil {
+			log.Printf("control: %v", err)
+			return
+		}
+		if err := enc.Encode(notification{JSONRPC: "2.0", Method: MethodStatus, Params: params}); err != nil {
+			return
+		}
+	}
+}
+
+// handle decodes one request line and returns the response. reply is
+// false for notifications, which get no response unless the line is not
+// a valid request at all. watch reports whether the client asked to
+// watch.
+func (s *Server) handle(line []byte) (resp response, reply, watch bool) {
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return errorResponse(nil, CodeParseError, "invalid JSON: "+err.Error()), true, false
+	}
+	if !validID(req.ID) {
+		return errorResponse(nil, CodeInvalidRequest, "id must be a string or a number"), true, false
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return errorResponse(req.ID, CodeInvalidRequest, "not a JSON-RPC 2.0 request"), true, false
+	}
+	resp, watch = s.call(req)
+	return resp, req.ID != nil, watch
+}
+
+// validID reports whether id is absent or one of the JSON values
+// JSON-RPC allows as an id: a string, a number or null.
+func validID(id json.RawMessage) bool {
+	if id == nil {
+		return true
+	}
+	switch c := id[0]; {
+	case c == '"', c == '-', c >= '0' && c <= '9':
+		return true
+	}
+	return string(id) == "null"
+}
+
+// call carries out a valid request.
+func (s *Server) call(req request) (resp response, watch bool) {
+	switch req.Method {
+	case MethodStatus:
+	case MethodStart:
+		var p StartParams
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			return errorResponse(req.ID, CodeInvalidParams, "start needs {\"kind\": ...}"), false
+		}
+		if err := app.StartNamed(s.app, p.Kind, p.Task); err != nil {
+			return errorResponse(req.ID, CodeInvalidParams, err.Error()), false
+		}
+	case MethodPause:
+		s.app.Pause()
+	case MethodResume:
+		s.app.Resume()
+	case MethodStop:
+		s.app.Skip()
+	case MethodWatch:
+		watch = true
+	default:
+		return errorResponse(req.ID, CodeMethodNotFound, fmt.Sprintf("unknown method %q", req.Method)), false
+	}
+
+	result, err := json.Marshal(status.Of(s.app))
+	if err != nil {
+		log.Printf("control: %v", err)
+		return errorResponse(req.ID, CodeInvalidRequest, err.Error()), false
+	}
+	return response{JSONRPC: "2.0", ID: req.ID, Result: result}, watch
+}
+
+func errorResponse(id json.RawMessage, code int, msg string) response {
+	return response{JSONRPC: "2.0", ID: id, Error: &Error{Code: code, Message: msg}}
+}
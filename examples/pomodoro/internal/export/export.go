@@ -0,0 +1,219 @@
+// Package export writes completed sessions from the history log in
+// formats other tools can import: CSV for time trackers, JSON, and
+// iCalendar (RFC 5545) for calendars.
+package export
+
+import (
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/history"
+)
+
+// Format names an export format.
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+	FormatICS  Format = "ics"
+)
+
+// ParseFormat returns the format named s.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatCSV, FormatJSON, FormatICS:
+		return f, nil
+	}
+	return "", fmt.Errorf("export: unknown format %q (want csv, json or ics)", s)
+}
+
+// Filter selects the completed sessions that started in [Since, Until).
+// Zero bounds are open. A session logged more than once counts by its
+// last record, as in the stats.
+func Filter(records []history.Record, since, until time.Time) []history.Record {
+	var out []history.Record
+	for _, r := range history.Dedupe(records) {
+		if r.Outcome != history.OutcomeCompleted {
+			continue
+		}
+		if !since.IsZero() && r.Start.Before(since) {
+			continue
+		}
+		if !until.IsZero() && !r.Start.Before(until) {
+			continue
+		}
+		out = append(out, r)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	return out
+}
+
+// Write writes records to w in format f. Times in CSV are in loc.
+func Write(w io.Writer, f Format, records []history.Record, loc *time.Location) error {
+	switch f {
+	case FormatCSV:
+		return WriteCSV(w, records, loc)
+	case FormatJSON:
+		return WriteJSON(w, records)
+	case FormatICS:
+		return WriteICS(w, records)
+	}
+	return fmt.Errorf("export: unknown format %q", f)
+}
+
+// csvHeader names the CSV columns. Separate date and time columns and an
+// HH:MM:SS duration are what time tracking tools such as Toggl and
+// Clockify expect when importing.
+var csvHeader = []string{"Start date", "Start time", "End date", "End time", "Duration", "Description", "Kind"}
+
+// WriteCSV writes one row per record with local dates and times. The
+// duration is the time the session ran, without pauses.
+func WriteCSV(w io.Writer, records []history.Record, loc *time.Location) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, r := range records {
+		start, end := r.Start.In(loc), r.End.In(loc)
+		row := []string{
+			start.Format("2006-01-02"),
+			start.Format("15:04:05"),
+			end.Format("2006-01-02"),
+			end.Format("15:04:05"),
+			clockDuration(r.Elapsed),
+			summary(r),
+			r.Kind,
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes records as a JSON array in the history log format.
+func WriteJSON(w io.Writer, records []history.Record) error {
+	if records == nil {
+		records = []history.Record{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(records)
+}
+
+// WriteICS writes an iCalendar with one VEVENT per record. Event UIDs
+// are derived from the session kind and start, so importing an overlapping
+// export again updates events instead of duplicating them.
+func WriteICS(w io.Writer, records []history.Record) error {
+	iw := &icsWriter{w: w}
+	iw.line("BEGIN:VCALENDAR")
+	iw.line("VERSION:2.0")
+	iw.line("PRODID:-//co0p//pomodoro//EN")
+	iw.line("CALSCALE:GREGORIAN")
+	for _, r := range records {
+		iw.line("BEGIN:VEVENT")
+		iw.line("UID:" + UID(r))
+		// the end of the session keeps DTSTAMP stable across exports
+		iw.line("DTSTAMP:" + icsTime(r.End))
+		iw.line("DTSTART:" + icsTime(r.Start))
+		iw.line("DTEND:" + icsTime(r.End))
+		iw.line("SUMMARY:" + icsText(summary(r)))
+		if d := description(r); d != "" {
+			iw.line("DESCRIPTION:" + icsText(d))
+		}
+		iw.line("CATEGORIES:" + icsText(r.Kind))
+		iw.line("END:VEVENT")
+	}
+	iw.line("END:VCALENDAR")
+	return iw.err
+}
+
+// UID returns the stable identifier of the session r.
+func UID(r history.Record) string {
+	sum := sha1.Sum([]byte(r.Kind + "|" + r.Start.UTC().Format(time.RFC3339Nano)))
+	return hex.EncodeToString(sum[:10]) + "@pomodoro"
+}
+
+// summary is the title of a session: its task label, or its kind.
+func summary(r history.Record) string {
+	if r.Label != "" {
+		return r.Label
+	}
+	switch r.Kind {
+	case "pomodoro":
+		return "Pomodoro"
+	case "short-break":
+		return "Short break"
+	case "long-break":
+		return "Long break"
+	}
+	return r.Kind
+}
+
+// description lists details that do not fit the summary.
+func description(r history.Record) string {
+	var parts []string
+	if r.Label != "" {
+		parts = append(parts, "Pomodoro")
+	}
+	if n := r.InternalInterruptions + r.ExternalInterruptions; n > 0 {
+		parts = append(parts, fmt.Sprintf("%d internal, %d external interruptions",
+			r.InternalInterruptions, r.ExternalInterruptions))
+	}
+	return strings.Join(parts, "\n")
+}
+
+// clockDuration formats d as HH:MM:SS.
+func clockDuration(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	s := int(d.Round(time.Second) / time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", s/3600, s/60%60, s%60)
+}
+
+func icsTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// icsText escapes a TEXT value (RFC 5545, 3.3.11).
+func icsText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// icsWriter writes content lines terminated by CRLF and folded at 75
+// octets (RFC 5545, 3.1). The first error is kept.
+type icsWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (iw *icsWriter) line(s string) {
+	if iw.err != nil {
+		return
+	}
+	var b strings.Builder
+	// continuation lines start with a space, which counts as well
+	for max := 75; len(s) > max; max = 74 {
+		// do not split UTF-8 sequences
+		n := max
+		for n > 0 && s[n]&0xC0 == 0x80 {
+			n--
+		}
+		b.WriteString(s[:n])
+		b.WriteString("\r\n ")
+		s = s[n:]
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+	_, iw.err = io.WriteString(iw.w, b.String())
+}
@@ -0,0 +1,155 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/history"
+)
+
+var day = time.Date(2025, 12, 5, 9, 0, 0, 0, time.UTC)
+
+func records() []history.Record {
+	return []history.Record{
+		// paused for ten minutes
+		{Kind: "pomodoro", Start: day.Add(time.Hour), End: day.Add(95 * time.Minute), Planned: 25 * time.Minute, Elapsed: 25 * time.Minute, Outcome: history.OutcomeCompleted},
+		{Kind: "pomodoro", Start: day, End: day.Add(25 * time.Minute), Planned: 25 * time.Minute, Elapsed: 25 * time.Minute, Outcome: history.OutcomeCompleted, Label: "ABC-1, review; part 2", ExternalInterruptions: 1},
+		{Kind: "short-break", Start: day.Add(25 * time.Minute), End: day.Add(27 * time.Minute), Planned: 5 * time.Minute, Elapsed: 2 * time.Minute, Outcome: history.OutcomeCancelled},
+		{Kind: "pomodoro", Start: day.AddDate(0, 0, 1), End: day.AddDate(0, 0, 1).Add(25 * time.Minute), Planned: 25 * time.Minute, Elapsed: 25 * time.Minute, Outcome: history.OutcomeCompleted},
+	}
+}
+
+func TestFilter(t *testing.T) {
+	got := Filter(records(), day, day.AddDate(0, 0, 1))
+	if len(got) != 2 {
+		t.Fatalf("expected the two completed sessions of the day, got %+v", got)
+	}
+	if !got[0].Start.Equal(day) || !got[1].Start.Equal(day.Add(time.Hour)) {
+		t.Fatalf("expected sessions ordered by start, got %+v", got)
+	}
+	if n := len(Filter(records(), time.Time{}, time.Time{})); n != 3 {
+		t.Fatalf("expected open bounds to keep all completed sessions, got %d", n)
+	}
+
+	// a session shut down and restored is logged again when it ends
+	shutdown := records()[1]
+	shutdown.End = day.Add(10 * time.Minute)
+	shutdown.Elapsed = 10 * time.Minute
+	shutdown.Outcome = history.OutcomeCancelled
+	restored := records()[1]
+	got = Filter(append([]history.Record{shutdown}, append(records(), restored)...), day, day.AddDate(0, 0, 1))
+	if len(got) != 2 || got[0] != restored {
+		t.Fatalf("expected the session to appear once, as last logged, got %+v", got)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	loc := time.FixedZone("CET", 3600)
+	var b bytes.Buffer
+	if err := WriteCSV(&b, Filter(records(), day, day.AddDate(0, 0, 1)), loc); err != nil {
+		t.Fatal(err)
+	}
+	want := "Start date,Start time,End date,End time,Duration,Description,Kind\n" +
+		"2025-12-05,10:00:00,2025-12-05,10:25:00,00:25:00,\"ABC-1, review; part 2\",pomodoro\n" +
+		"2025-12-05,11:00:00,2025-12-05,11:35:00,00:25:00,Pomodoro,pomodoro\n"
+	if b.String() != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteJSON(&b, nil); err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(b.String()) != "[]" {
+		t.Fatalf("expected an empty array, got %q", b.String())
+	}
+
+	b.Reset()
+	if err := WriteJSON(&b, Filter(records(), day, time.Time{})); err != nil {
+		t.Fatal(err)
+	}
+	var got []history.Record
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0].Label != "ABC-1, review; part 2" {
+		t.Fatalf("unexpected round trip %+v", got)
+	}
+}
+
+func TestWriteICS(t *testing.T) {
+	recs := Filter(records(), day, day.AddDate(0, 0, 1))
+	var b bytes.Buffer
+	if err := WriteICS(&b, recs); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\n",
+		"UID:" + UID(recs[0]) + "\r\n",
+		"DTSTART:20251205T090000Z\r\nDTEND:20251205T092500Z\r\n",
+		"SUMMARY:ABC-1\\, review\\; part 2\r\n",
+		"DESCRIPTION:Pomodoro\\n0 internal\\, 1 external interruptions\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, "BEGIN:VEVENT"); n != 2 {
+		t.Fatalf("expected 2 events, got %d", n)
+	}
+	for _, l := range strings.Split(out, "\r\n") {
+		if len(l) > 75 {
+			t.Fatalf("line longer than 75 octets: %q", l)
+		}
+	}
+
+	// exporting again yields identical output, so re-imports update events
+	var again bytes.Buffer
+	_ = WriteICS(&again, recs)
+	if again.String() != out {
+		t.Fatal("expected a stable export")
+	}
+	if UID(recs[0]) == UID(recs[1]) {
+		t.Fatal("expected distinct UIDs per session")
+	}
+}
+
+func TestICSFoldsLongLines(t *testing.T) {
+	r := records()[1]
+	r.Label = strings.Repeat("ü", 60)
+	var b bytes.Buffer
+	if err := WriteICS(&b, []history.Record{r}); err != nil {
+		t.Fatal(err)
+	}
+	var unfolded strings.Builder
+	for _, l := range strings.Split(b.String(), "\r\n") {
+		if len(l) > 75 {
+			t.Fatalf("line longer than 75 octets: %q", l)
+		}
+		if strings.HasPrefix(l, " ") {
+			unfolded.WriteString(l[1:])
+			continue
+		}
+		unfolded.WriteString("\n" + l)
+	}
+	if !strings.Contains(unfolded.String(), "SUMMARY:"+r.Label) {
+		t.Fatalf("expected the folded summary to unfold to the label, got %q", unfolded.String())
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if f, err := ParseFormat("ICS"); err != nil || f != FormatICS {
+		t.Fatalf("got %q, %v", f, err)
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Fatal("expected an unknown format to be rejected")
+	}
+}
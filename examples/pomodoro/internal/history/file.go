@@ -0,0 +1,133 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileStore is a Store backed by a JSON-lines file. Each Append writes a
+// whole line with a single write and syncs it to disk, so a crash can at
+// worst leave a truncated last line, which Load skips and the next Append
+// terminates.
+type FileStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileStore returns a store that reads and appends to the file at
+// path. The file and its directory are created on the first Append.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Path returns the location of the history file.
+func (s *FileStore) Path() string { return s.path }
+
+// Append writes r as a new line at the end of the file.
+func (s *FileStore) Append(r Record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// a previous crash may have left a line without its newline: finish it
+	// so the new record starts on a line of its own
+	torn, err := endsMidLine(f)
+	if err != nil {
+		return err
+	}
+	line := make([]byte, 0, len(b)+2)
+	if torn {
+		line = append(line, '\n')
+	}
+	line = append(line, b...)
+	line = append(line, '\n')
+
+	if _, err := f.Write(line); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// Load reads all records from the file. A missing file is an empty
+// history.
+func (s *FileStore) Load() ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	recs, bad, err := decodeLines(f)
+	if err != nil {
+		return recs, err
+	}
+	if len(bad) > 0 {
+		return recs, &MalformedError{Path: s.path, Lines: bad}
+	}
+	return recs, nil
+}
+
+// decodeLines parses one record per line, returning the valid records and
+// the 1-based numbers of lines that could not be parsed. Blank lines are
+// ignored.
+func decodeLines(r io.Reader) ([]Record, []int, error) {
+	var recs []Record
+	var bad []int
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 4096), 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			bad = append(bad, n)
+			continue
+		}
+		recs = append(recs, rec)
+	}
+	return recs, bad, sc.Err()
+}
+
+// endsMidLine reports whether f is non-empty and its last byte is not a
+// newline.
+func endsMidLine(f *os.File) (bool, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+	if fi.Size() == 0 {
+		return false, nil
+	}
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, fi.Size()-1); err != nil {
+		return false, err
+	}
+	return last[0] != '\n', nil
+}
@@ -0,0 +1,134 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2025, 12, 5, 9, 0, 0, 0, time.UTC)
+
+func record(kind string, offset time.Duration, outcome Outcome) Record {
+	return Record{
+		Kind:    kind,
+		Start:   start.Add(offset),
+		End:     start.Add(offset + 25*time.Minute),
+		Planned: 25 * time.Minute,
+		Outcome: outcome,
+	}
+}
+
+func TestFileStoreAppendAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "history.jsonl")
+	s := NewFileStore(path)
+
+	recs, err := s.Load()
+	if err != nil || len(recs) != 0 {
+		t.Fatalf("expected empty history for missing file, got %v, %v", recs, err)
+	}
+
+	want := []Record{
+		record("pomodoro", 0, OutcomeCompleted),
+		record("short-break", 30*time.Minute, OutcomeSuperseded),
+	}
+	want[1].Label = "TICKET-42"
+	for _, r := range want {
+		if err := s.Append(r); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+
+	got, err := s.Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("round trip mismatch:\n got %+v\nwant %+v", got, want)
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := fi.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("expected 0600 history file, got %v", perm)
+	}
+}
+
+func TestFileStoreSkipsMalformedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	good := `{"kind":"pomodoro","start":"2025-12-05T09:00:00Z","end":"2025-12-05T09:25:00Z","planned":"25m0s","outcome":"completed"}`
+	content := good + "\n" +
+		"not json\n" +
+		"\n" +
+		`{"kind":"pomodoro","outcome":"completed"}` + "\n" +
+		good + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	recs, err := NewFileStore(path).Load()
+	var me *MalformedError
+	if !errors.As(err, &me) {
+		t.Fatalf("expected MalformedError, got %v", err)
+	}
+	if !reflect.DeepEqual(me.Lines, []int{2, 4}) {
+		t.Fatalf("unexpected malformed lines %v", me.Lines)
+	}
+	if len(recs) != 2 {
+		t.Fatalf("expected the 2 valid records, got %d", len(recs))
+	}
+}
+
+func TestFileStoreRecoversFromTornWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	s := NewFileStore(path)
+	if err := s.Append(record("pomodoro", 0, OutcomeCompleted)); err != nil {
+		t.Fatal(err)
+	}
+
+	// simulate a crash in the middle of writing the next line
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"kind":"pomo`); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	if err := s.Append(record("pomodoro", time.Hour, OutcomeCancelled)); err != nil {
+		t.Fatal(err)
+	}
+
+	recs, err := s.Load()
+	var me *MalformedError
+	if !errors.As(err, &me) || !reflect.DeepEqual(me.Lines, []int{2}) {
+		t.Fatalf("expected only the torn line to be malformed, got %v", err)
+	}
+	if len(recs) != 2 || recs[1].Outcome != OutcomeCancelled {
+		t.Fatalf("expected both complete records to survive, got %+v", recs)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	m := NewMemoryStore()
+	r := record("long-break", 0, OutcomeShutdown)
+	if err := m.Append(r); err != nil {
+		t.Fatal(err)
+	}
+	recs, err := m.Load()
+	if err != nil || len(recs) != 1 || recs[0] != r {
+		t.Fatalf("unexpected load %+v, %v", recs, err)
+	}
+
+	// callers must not be able to mutate the store through Load
+	recs[0].Kind = "changed"
+	again, _ := m.Load()
+	if again[0].Kind != "long-break" {
+		t.Fatal("Load returned shared storage")
+	}
+}
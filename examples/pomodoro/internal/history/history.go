@@ -0,0 +1,177 @@
+// Package history keeps an append-only log of finished pomodoro sessions.
+// Each session is one JSON object per line, so the log can be inspected
+// with standard tools and survives partial writes.
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Outcome tells how a session ended.
+type Outcome string
+
+const (
+	OutcomeCompleted  Outcome = "completed"
+	OutcomeCancelled  Outcome = "cancelled"
+	OutcomeSuperseded Outcome = "superseded"
+	OutcomeShutdown   Outcome = "shutdown"
+	OutcomeExpired    Outcome = "expired"
+)
+
+// Record is a single finished session.
+type Record struct {
+	// Kind is the session kind, for example "pomodoro" or "short-break".
+	Kind    string
+	Start   time.Time
+	End     time.Time
+	Planned time.Duration
+	// Elapsed is how long the session actually ran, without pauses or
+	// time the app was not running.
+	Elapsed time.Duration
+	Outcome Outcome
+	// Label optionally names the task the session was spent on.
+	Label string
+	// InternalInterruptions and ExternalInterruptions count the
+	// interruptions recorded during a pomodoro.
+	InternalInterruptions int
+	ExternalInterruptions int
+}
+
+// recordJSON is the on-disk form of a Record. Durations are stored as Go
+// duration strings such as "25m0s" to keep the log readable.
+type recordJSON struct {
+	Kind    string    `json:"kind"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Planned string    `json:"planned"`
+	Elapsed string    `json:"elapsed,omitempty"`
+	Outcome Outcome   `json:"outcome"`
+	Label   string    `json:"label,omitempty"`
+	// interruption counts are omitted when zero
+	Internal int `json:"internal_interruptions,omitempty"`
+	External int `json:"external_interruptions,omitempty"`
+}
+
+// MarshalJSON encodes r in the history log format.
+func (r Record) MarshalJSON() ([]byte, error) {
+	return json.Marshal(recordJSON{
+		Kind:     r.Kind,
+		Start:    r.Start,
+		End:      r.End,
+		Planned:  r.Planned.String(),
+		Elapsed:  r.Elapsed.String(),
+		Outcome:  r.Outcome,
+		Label:    r.Label,
+		Internal: r.InternalInterruptions,
+		External: r.ExternalInterruptions,
+	})
+}
+
+// UnmarshalJSON decodes r from the history log format. It rejects records
+// without a kind, outcome or start time. Records written before the run
+// time was kept get the time from start to end as Elapsed.
+func (r *Record) UnmarshalJSON(b []byte) error {
+	var j recordJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	if j.Kind == "" || j.Outcome == "" || j.Start.IsZero() {
+		return fmt.Errorf("missing kind, outcome or start")
+	}
+	planned, err := time.ParseDuration(j.Planned)
+	if err != nil {
+		return fmt.Errorf("planned: %w", err)
+	}
+	var elapsed time.Duration
+	if j.Elapsed != "" {
+		if elapsed, err = time.ParseDuration(j.Elapsed); err != nil {
+			return fmt.Errorf("elapsed: %w", err)
+		}
+	} else if !j.End.IsZero() && j.End.After(j.Start) {
+		elapsed = j.End.Sub(j.Start)
+	}
+	*r = Record{
+		Kind:                  j.Kind,
+		Start:                 j.Start,
+		End:                   j.End,
+		Planned:               planned,
+		Elapsed:               elapsed,
+		Outcome:               j.Outcome,
+		Label:                 j.Label,
+		InternalInterruptions: j.Internal,
+		ExternalInterruptions: j.External,
+	}
+	return nil
+}
+
+// RecentLabels returns up to n distinct labels of records, most recently
+// started first. Records without a label are skipped.
+func RecentLabels(records []Record, n int) []string {
+	sorted := append([]Record(nil), records...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start.After(sorted[j].Start) })
+	var labels []string
+	seen := make(map[string]bool)
+	for _, r := range sorted {
+		if len(labels) == n {
+			break
+		}
+		if r.Label == "" || seen[r.Label] {
+			continue
+		}
+		seen[r.Label] = true
+		labels = append(labels, r.Label)
+	}
+	return labels
+}
+
+// Dedupe keeps the last record for every kind and start time, in the
+// original order. A session that was shut down and later restored is
+// logged again under the same kind and start when it ends.
+func Dedupe(records []Record) []Record {
+	type key struct {
+		kind  string
+		start int64
+	}
+	last := make(map[key]int, len(records))
+	for i, r := range records {
+		last[key{r.Kind, r.Start.UnixNano()}] = i
+	}
+	out := make([]Record, 0, len(last))
+	for i, r := range records {
+		if last[key{r.Kind, r.Start.UnixNano()}] == i {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// Store persists finished sessions.
+type Store interface {
+	// Append adds r to the end of the store.
+	Append(r Record) error
+	// Load returns all records in append order. When some entries cannot
+	// be read it returns the valid records together with a
+	// `*MalformedError`.
+	Load() ([]Record, error)
+}
+
+// MalformedError reports entries of a history log that could not be
+// parsed. The valid entries are still returned by `Store.Load`.
+type MalformedError struct {
+	Path  string
+	Lines []int
+}
+
+func (e *MalformedError) Error() string {
+	lines := make([]string, len(e.Lines))
+	for i, n := range e.Lines {
+		lines[i] = strconv.Itoa(n)
+	}
+	return fmt.Sprintf("history: %s: skipped %d malformed line(s): %s",
+		e.Path, len(e.Lines), strings.Join(lines, ", "))
+}
@@ -0,0 +1,62 @@
+package history
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRecentLabels(t *testing.T) {
+	at := start
+	recs := []Record{
+		{Kind: "pomodoro", Start: at, Label: "a"},
+		{Kind: "pomodoro", Start: at.Add(2 * time.Hour), Label: "b"},
+		{Kind: "short-break", Start: at.Add(3 * time.Hour)},
+		{Kind: "pomodoro", Start: at.Add(time.Hour), Label: "c"},
+		{Kind: "pomodoro", Start: at.Add(4 * time.Hour), Label: "a"},
+	}
+	if got, want := RecentLabels(recs, 2), []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if got, want := RecentLabels(recs, 10), []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestRecordElapsed(t *testing.T) {
+	at := start
+	r := Record{Kind: "pomodoro", Start: at, End: at.Add(2 * time.Hour), Planned: 25 * time.Minute, Elapsed: 25 * time.Minute, Outcome: OutcomeCompleted}
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Record
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != r {
+		t.Fatalf("round trip:\n got %+v\nwant %+v", got, r)
+	}
+
+	// lines written before the run time was kept fall back to the wall time
+	old := `{"kind":"pomodoro","start":"2025-12-05T09:00:00Z","end":"2025-12-05T09:25:00Z","planned":"25m0s","outcome":"completed"}`
+	if err := json.Unmarshal([]byte(old), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Elapsed != 25*time.Minute {
+		t.Fatalf("expected 25m elapsed for an old record, got %s", got.Elapsed)
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	recs := []Record{
+		{Kind: "pomodoro", Start: start, Outcome: OutcomeCancelled},
+		{Kind: "short-break", Start: start},
+		{Kind: "pomodoro", Start: start.Add(time.Hour)},
+		{Kind: "pomodoro", Start: start, Outcome: OutcomeCompleted},
+	}
+	if got, want := Dedupe(recs), recs[1:]; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
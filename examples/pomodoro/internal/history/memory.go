@@ -0,0 +1,27 @@
+package history
+
+import "sync"
+
+// MemoryStore is an in-memory Store for tests.
+type MemoryStore struct {
+	mu   sync.Mutex
+	recs []Record
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore { return &MemoryStore{} }
+
+// Append adds r to the store.
+func (m *MemoryStore) Append(r Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recs = append(m.recs, r)
+	return nil
+}
+
+// Load returns a copy of the stored records.
+func (m *MemoryStore) Load() ([]Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Record(nil), m.recs...), nil
+}
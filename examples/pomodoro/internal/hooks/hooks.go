@@ -0,0 +1,291 @@
+// Package hooks runs user scripts when the timer changes state, for
+// example to mute chat while a pomodoro runs. Scripts live in a hooks.d
+// directory and are named after the event they handle, optionally with
+// an extension: `pomodoro-start`, `pomodoro-complete.sh`, `break-stop`.
+//
+// Event names combine the session, "pomodoro" or "break", with what
+// happened:
+//
+//	start      the session started, or was restored after a restart
+//	complete   the session ran for its full duration
+//	stop       the session ended early: skipped, replaced by another
+//	           session, or ended because the app quit
+//	pause, resume, extend, interrupt
+//
+// Hooks run with the environment of the app plus POMODORO_* variables
+// describing the event, see `Env`.
+package hooks
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+)
+
+const (
+	// DefaultTimeout bounds the run time of a single hook.
+	DefaultTimeout = 10 * time.Second
+	// DefaultConcurrency is how many hooks may run at the same time.
+	DefaultConcurrency = 2
+	// queueSize bounds the events waiting for their hooks; further
+	// events are dropped so the app is never blocked.
+	queueSize = 64
+	// waitDelay bounds waiting for the output of a killed hook whose
+	// children keep it open.
+	waitDelay = time.Second
+)
+
+// Options configure a Runner. Zero fields use the defaults.
+type Options struct {
+	// Timeout bounds each hook run; a hook still running is killed.
+	Timeout time.Duration
+	// Concurrency is the maximum number of hooks running at once.
+	Concurrency int
+	// Logger receives the output and failures of hooks.
+	Logger *log.Logger
+}
+
+// Runner runs the hooks in a directory for the events of an app.
+type Runner struct {
+	dir   string
+	opts  Options
+	queue chan job
+	slots chan struct{}
+	wg    sync.WaitGroup
+}
+
+// job is an event waiting for its hooks.
+type job struct {
+	name string
+	env  []string
+}
+
+// New returns a Runner for the hooks in dir. The directory is read for
+// every event, so hooks can be added or removed while the app runs.
+func New(dir string, o Options) *Runner {
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultTimeout
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultConcurrency
+	}
+	if o.Logger == nil {
+		o.Logger = log.Default()
+	}
+	return &Runner{
+		dir:   dir,
+		opts:  o,
+		queue: make(chan job, queueSize),
+		slots: make(chan struct{}, o.Concurrency),
+	}
+}
+
+// Attach runs hooks for the events of a until the returned function is
+// called, which then waits for the hooks still running. Events are only
+// queued on the app's notification path; hooks run in the background so
+// a slow hook never delays the app. Hooks start in event order but may
+// finish in any order. A Runner is attached to one app only.
+func (r *Runner) Attach(a app.App) (detach func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.dispatch()
+	}()
+
+	var mu sync.Mutex
+	stopped := false
+	unsubscribe := a.SubscribeEvents(func(e app.Event) {
+		name := Name(e)
+		if name == "" {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if stopped {
+			return
+		}
+		select {
+		case r.queue <- job{name: name, env: Env(name, e, a.Cycle())}:
+		default:
+			r.opts.Logger.Printf("hook %s skipped: too many pending hooks", name)
+		}
+	})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			unsubscribe()
+			mu.Lock()
+			stopped = true
+			close(r.queue)
+			mu.Unlock()
+			<-done
+			r.wg.Wait()
+		})
+	}
+}
+
+// dispatch starts the hooks of queued events, waiting for a free slot
+// when Concurrency hooks are running.
+func (r *Runner) dispatch() {
+	for j := range r.queue {
+		for _, path := range r.find(j.name) {
+			r.slots <- struct{}{}
+			r.wg.Add(1)
+			go func(path string, env []string) {
+				defer func() {
+					<-r.slots
+					r.wg.Done()
+				}()
+				r.run(path, env)
+			}(path, j.env)
+		}
+	}
+}
+
+// find returns the executable hooks for the event name, sorted by file
+// name.
+func (r *Runner) find(name string) []string {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			r.opts.Logger.Printf("hooks: %v", err)
+		}
+		return nil
+	}
+	var found []string
+	for _, e := range entries {
+		if e.IsDir() || !matches(e.Name(), name) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if runtime.GOOS != "windows" && info.Mode().Perm()&0o111 == 0 {
+			r.opts.Logger.Printf("hook %s ignored: not executable", e.Name())
+			continue
+		}
+		found = append(found, filepath.Join(r.dir, e.Name()))
+	}
+	sort.Strings(found)
+	return found
+}
+
+// matches reports whether file is a hook for the event name: the name
+// itself or the name with an extension. Hidden and backup files never
+// match.
+func matches(file, name string) bool {
+	if strings.HasPrefix(file, ".") || strings.HasSuffix(file, "~") {
+		return false
+	}
+	return strings.TrimSuffix(file, filepath.Ext(file)) == name || file == name
+}
+
+// run executes the hook at path and logs its output and outcome.
+func (r *Runner) run(path string, env []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.opts.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Dir = r.dir
+	cmd.Env = append(os.Environ(), env...)
+	cmd.WaitDelay = waitDelay
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	start := time.Now()
+	err := cmd.Run()
+	file := filepath.Base(path)
+	sc := bufio.NewScanner(&out)
+	for sc.Scan() {
+		r.opts.Logger.Printf("hook %s: %s", file, sc.Text())
+	}
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		r.opts.Logger.Printf("hook %s killed after %s", file, r.opts.Timeout)
+	case err != nil:
+		r.opts.Logger.Printf("hook %s failed after %s: %v", file, time.Since(start).Round(time.Millisecond), err)
+	}
+}
+
+// Name returns the hook name for e, or "" when e runs no hooks.
+func Name(e app.Event) string {
+	if e.Kind == "" {
+		return ""
+	}
+	session := "break"
+	if e.Kind == app.KindPomodoro {
+		session = "pomodoro"
+	}
+	var action string
+	switch e.Reason {
+	case app.ReasonStarted, app.ReasonRestored:
+		action = "start"
+	case app.ReasonCompleted:
+		action = "complete"
+	case app.ReasonCancelled, app.ReasonSuperseded, app.ReasonShutdown, app.ReasonExpired:
+		action = "stop"
+	case app.ReasonPaused:
+		action = "pause"
+	case app.ReasonResumed:
+		action = "resume"
+	case app.ReasonExtended:
+		action = "extend"
+	case app.ReasonInterrupted:
+		action = "interrupt"
+	default:
+		return ""
+	}
+	return session + "-" + action
+}
+
+// Env returns the variables describing e to a hook named name:
+//
+//	POMODORO_EVENT             the hook name, e.g. "pomodoro-start"
+//	POMODORO_KIND              "pomodoro", "short-break" or "long-break"
+//	POMODORO_REASON            the app's reason, e.g. "completed"
+//	POMODORO_FROM, POMODORO_TO the states before and after
+//	POMODORO_TASK              the task label, if any
+//	POMODORO_PLANNED           the planned length in seconds
+//	POMODORO_STARTED, POMODORO_END, POMODORO_AT
+//	                           RFC 3339 times; END only while running
+//	POMODORO_INTERRUPTIONS_INTERNAL, POMODORO_INTERRUPTIONS_EXTERNAL
+//	POMODORO_CYCLE_COMPLETED, POMODORO_CYCLE_LENGTH
+func Env(name string, e app.Event, c app.Cycle) []string {
+	env := []string{
+		"POMODORO_EVENT=" + name,
+		"POMODORO_KIND=" + string(e.Kind),
+		"POMODORO_REASON=" + string(e.Reason),
+		"POMODORO_FROM=" + string(e.From),
+		"POMODORO_TO=" + string(e.To),
+		"POMODORO_TASK=" + e.Task,
+		"POMODORO_PLANNED=" + strconv.Itoa(int(e.Planned/time.Second)),
+		"POMODORO_AT=" + e.At.Format(time.RFC3339),
+		fmt.Sprintf("POMODORO_INTERRUPTIONS_INTERNAL=%d", e.Interruptions.Internal),
+		fmt.Sprintf("POMODORO_INTERRUPTIONS_EXTERNAL=%d", e.Interruptions.External),
+		fmt.Sprintf("POMODORO_CYCLE_COMPLETED=%d", c.Completed),
+		fmt.Sprintf("POMODORO_CYCLE_LENGTH=%d", c.Length),
+	}
+	if !e.Started.IsZero() {
+		env = append(env, "POMODORO_STARTED="+e.Started.Format(time.RFC3339))
+	}
+	if !e.End.IsZero() {
+		env = append(env, "POMODORO_END="+e.End.Format(time.RFC3339))
+	}
+	return env
+}
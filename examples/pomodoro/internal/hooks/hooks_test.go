@@ -0,0 +1,160 @@
+package hooks
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+	"github.com/co0p/4dc/examples/pomodoro/internal/apptest"
+)
+
+// writeHook creates an executable shell script in dir.
+func writeHook(t *testing.T, dir, name, script string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func skipOnWindows(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks in these tests are shell scripts")
+	}
+}
+
+func TestHooksRunWithEventEnvironment(t *testing.T) {
+	skipOnWindows(t)
+	dir := t.TempDir()
+	out := filepath.Join(t.TempDir(), "env")
+	writeHook(t, dir, "pomodoro-start.sh", `env | grep ^POMODORO_ | sort > "`+out+`"; echo started $POMODORO_TASK`)
+	writeHook(t, dir, "pomodoro-start.sh~", `echo backup files do not run`)
+	writeHook(t, dir, "break-start", `echo breaks do not match`)
+	logs := &apptest.SyncBuffer{}
+
+	a, _ := apptest.NewApp(t)
+	detach := New(dir, Options{Logger: log.New(logs, "", 0)}).Attach(a)
+	a.StartPomodoroFor("ABC-123")
+	detach()
+
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("hook did not run: %v", err)
+	}
+	for _, want := range []string{
+		"POMODORO_EVENT=pomodoro-start",
+		"POMODORO_KIND=pomodoro",
+		"POMODORO_REASON=started",
+		"POMODORO_TO=PomodoroRunning",
+		"POMODORO_TASK=ABC-123",
+		"POMODORO_PLANNED=1500",
+		"POMODORO_END=2025-12-05T09:25:00Z",
+		"POMODORO_CYCLE_LENGTH=4",
+	} {
+		if !strings.Contains(string(b), want+"\n") {
+			t.Errorf("hook environment lacks %s:\n%s", want, b)
+		}
+	}
+	if got := logs.String(); got != "hook pomodoro-start.sh: started ABC-123\n" {
+		t.Fatalf("unexpected log %q", got)
+	}
+}
+
+func TestSlowHookDoesNotBlockTheApp(t *testing.T) {
+	skipOnWindows(t)
+	dir := t.TempDir()
+	writeHook(t, dir, "pomodoro-start", "exec sleep 10")
+	logs := &apptest.SyncBuffer{}
+
+	a, _ := apptest.NewApp(t)
+	detach := New(dir, Options{Timeout: 100 * time.Millisecond, Logger: log.New(logs, "", 0)}).Attach(a)
+	begin := time.Now()
+	for i := 0; i < 3; i++ {
+		a.StartPomodoro()
+		a.Skip()
+	}
+	if d := time.Since(begin); d > 50*time.Millisecond {
+		t.Fatalf("starting sessions took %s with a hanging hook", d)
+	}
+	detach()
+	if d := time.Since(begin); d > 5*time.Second {
+		t.Fatalf("hooks were not killed at their timeout, detach took %s", d)
+	}
+	if n := strings.Count(logs.String(), "hook pomodoro-start killed after 100ms"); n != 3 {
+		t.Fatalf("expected 3 killed hooks, got log:\n%s", logs.String())
+	}
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	skipOnWindows(t)
+	dir := t.TempDir()
+	lock := filepath.Join(t.TempDir(), "running")
+	// mkdir is atomic: it fails when another hook is running
+	writeHook(t, dir, "pomodoro-start", `mkdir "`+lock+`" || echo overlap; sleep 0.05; rmdir "`+lock+`"`)
+	logs := &apptest.SyncBuffer{}
+
+	a, _ := apptest.NewApp(t)
+	detach := New(dir, Options{Concurrency: 1, Logger: log.New(logs, "", 0)}).Attach(a)
+	for i := 0; i < 4; i++ {
+		a.StartPomodoro()
+		a.Skip()
+	}
+	detach()
+	if strings.Contains(logs.String(), "overlap") {
+		t.Fatalf("hooks ran concurrently:\n%s", logs.String())
+	}
+}
+
+func TestFailingAndNonExecutableHooksAreLogged(t *testing.T) {
+	skipOnWindows(t)
+	dir := t.TempDir()
+	writeHook(t, dir, "pomodoro-start", "echo oops >&2; exit 3")
+	if err := os.WriteFile(filepath.Join(dir, "pomodoro-stop"), []byte("#!/bin/sh\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	logs := &apptest.SyncBuffer{}
+
+	a, _ := apptest.NewApp(t)
+	detach := New(dir, Options{Logger: log.New(logs, "", 0)}).Attach(a)
+	a.StartPomodoro()
+	a.Skip()
+	detach()
+
+	for _, want := range []string{
+		"hook pomodoro-start: oops",
+		"hook pomodoro-start failed after",
+		"exit status 3",
+		"hook pomodoro-stop ignored: not executable",
+	} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("log lacks %q:\n%s", want, logs.String())
+		}
+	}
+}
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		kind   app.Kind
+		reason app.Reason
+		want   string
+	}{
+		{app.KindPomodoro, app.ReasonStarted, "pomodoro-start"},
+		{app.KindPomodoro, app.ReasonRestored, "pomodoro-start"},
+		{app.KindPomodoro, app.ReasonCompleted, "pomodoro-complete"},
+		{app.KindPomodoro, app.ReasonSuperseded, "pomodoro-stop"},
+		{app.KindShortBreak, app.ReasonStarted, "break-start"},
+		{app.KindLongBreak, app.ReasonCancelled, "break-stop"},
+		{app.KindLongBreak, app.ReasonPaused, "break-pause"},
+		{app.KindPomodoro, app.ReasonInterrupted, "pomodoro-interrupt"},
+		{"", app.ReasonShutdown, ""},
+	}
+	for _, tt := range tests {
+		if got := Name(app.Event{Kind: tt.kind, Reason: tt.reason}); got != tt.want {
+			t.Errorf("Name(%s %s) = %q, want %q", tt.kind, tt.reason, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+// Package httpapi serves a small HTTP control API for the running app on
+// the loopback interface, so scripts and editor plugins can read the
+// timer state and start, pause, resume or stop sessions. Status bodies are
+// `status.Status` values encoded as JSON.
+//
+// Every request must carry `Authorization: Bearer <token>`. Routes:
+//
+//	GET  /status        current session as JSON
+//	GET  /events        server-sent events, one "state" event per change
+//	POST /start/{kind}  start a pomodoro, short-break, long-break or break;
+//	                    an optional ?task= labels a pomodoro
+//	POST /pause
+//	POST /resume
+//	POST /stop          abandon the active session
+package httpapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+	"github.com/co0p/4dc/examples/pomodoro/internal/status"
+)
+
+// keepAliveInterval is how often an idle event stream sends a comment so
+// clients and proxies keep the connection open.
+const keepAliveInterval = 30 * time.Second
+
+// Server is an http.Handler for the control API.
+type Server struct {
+	app   app.App
+	token string
+}
+
+// New returns a server controlling a that accepts requests carrying
+// token.
+func New(a app.App, token string) *Server {
+	return &Server{app: a, token: token}
+}
+
+// ListenAndServe serves the API on 127.0.0.1:port until ctx is done, then
+// closes open event streams and shuts down.
+func (s *Server) ListenAndServe(ctx context.Context, port int) error {
+	ln, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve serves the API on ln until ctx is done.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	srv := &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: 5 * time.Second,
+		// requests, including event streams, end with ctx
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(ln) }()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// ServeHTTP authenticates the request and dispatches it to a route.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="pomodoro"`)
+		writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+		return
+	}
+
+	path := r.URL.Path
+	switch {
+	case path == "/status":
+		if allow(w, r, http.MethodGet) {
+			writeJSON(w, http.StatusOK, status.Of(s.app))
+		}
+	case path == "/events":
+		if allow(w, r, http.MethodGet) {
+			s.events(w, r)
+		}
+	case strings.HasPrefix(path, "/start/"):
+		if allow(w, r, http.MethodPost) {
+			s.start(w, r, strings.TrimPrefix(path, "/start/"))
+		}
+	case path == "/pause":
+		s.action(w, r, s.app.Pause)
+	case path == "/resume":
+		s.action(w, r, s.app.Resume)
+	case path == "/stop":
+		s.action(w, r, s.app.Skip)
+	default:
+		writeError(w, http.StatusNotFound, "no such endpoint")
+	}
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	const prefix = "Bearer "
+	h := r.Header.Get("Authorization")
+	if !strings.HasPrefix(h, prefix) {
+		return false
+	}
+	got := strings.TrimSpace(strings.TrimPrefix(h, prefix))
+	return subtle.ConstantTimeCompare([]byte(got), []byte(s.token)) == 1
+}
+
+// start handles POST /start/{kind}.
+func (s *Server) start(w http.ResponseWriter, r *http.Request, kind string) {
+	if err := app.StartNamed(s.app, kind, r.URL.Query().Get("task")); err != nil {
+		code := http.StatusBadRequest
+		if errors.Is(err, app.ErrUnknownKind) {
+			code = http.StatusNotFound
+		}
+		writeError(w, code, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, status.Of(s.app))
+}
+
+// action handles a POST endpoint that calls fn and returns the status.
+func (s *Server) action(w http.ResponseWriter, r *http.Request, fn func()) {
+	if !allow(w, r, http.MethodPost) {
+		return
+	}
+	fn()
+	writeJSON(w, http.StatusOK, status.Of(s.app))
+}
+
+// events streams a "state" event with the current status on connect and
+// after every state change until the client goes away or the server
+// shuts down.
+func (s *Server) events(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+	changed := make(chan struct{}, 1)
+	unsubscribe := s.app.SubscribeStateChange(func(app.State) {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	send := func() bool {
+		b, err := json.Marshal(status.Of(s.app))
+		if err != nil {
+			log.Printf("httpapi: %v", err)
+			return false
+		}
+		if _, err := fmt.Fprintf(w, "event: state\ndata: %s\n\n", b); err != nil {
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+	if !send() {
+		return
+	}
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-changed:
+			if !send() {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// allow reports whether r uses method and answers 405 otherwise.
+func allow(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method {
+		return true
+	}
+	w.Header().Set("Allow", method)
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("httpapi: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, map[string]string{"error": msg})
+}
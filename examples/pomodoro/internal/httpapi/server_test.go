@@ -0,0 +1,223 @@
+package httpapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+	"github.com/co0p/4dc/examples/pomodoro/internal/apptest"
+	"github.com/co0p/4dc/examples/pomodoro/internal/clock"
+	"github.com/co0p/4dc/examples/pomodoro/internal/status"
+)
+
+const token = "s3cret"
+
+func newTestServer(t *testing.T) (app.App, *clock.Fake, *httptest.Server) {
+	t.Helper()
+	a, c := apptest.NewApp(t)
+	srv := httptest.NewServer(New(a, token))
+	t.Cleanup(srv.Close)
+	return a, c, srv
+}
+
+func do(t *testing.T, method, url, tok string) (*http.Response, status.Status) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok != "" {
+		req.Header.Set("Authorization", "Bearer "+tok)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var st status.Status
+	if resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(&st); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp, st
+}
+
+func TestRequiresBearerToken(t *testing.T) {
+	_, _, srv := newTestServer(t)
+
+	for _, tok := range []string{"", "wrong"} {
+		resp, _ := do(t, http.MethodGet, srv.URL+"/status", tok)
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("token %q: expected 401, got %d", tok, resp.StatusCode)
+		}
+	}
+}
+
+func TestControlEndpoints(t *testing.T) {
+	a, c, srv := newTestServer(t)
+
+	resp, st := do(t, http.MethodPost, srv.URL+"/start/pomodoro?task=ABC-1", token)
+	if resp.StatusCode != http.StatusOK || st.State != app.StatePomodoroRunning || st.Task != "ABC-1" {
+		t.Fatalf("unexpected start response %d %+v", resp.StatusCode, st)
+	}
+
+	c.Advance(5 * time.Minute)
+	_, st = do(t, http.MethodGet, srv.URL+"/status", token)
+	if st.Remaining != "20m0s" || st.RemainingSeconds != 1200 || st.End == nil || st.Cycle.Length != 4 {
+		t.Fatalf("unexpected status %+v", st)
+	}
+
+	if _, st = do(t, http.MethodPost, srv.URL+"/pause", token); st.State != app.StatePaused || st.End != nil {
+		t.Fatalf("expected paused without end, got %+v", st)
+	}
+	if _, st = do(t, http.MethodPost, srv.URL+"/resume", token); st.State != app.StatePomodoroRunning {
+		t.Fatalf("expected running, got %+v", st)
+	}
+	if _, st = do(t, http.MethodPost, srv.URL+"/stop", token); st.State != app.StateIdle {
+		t.Fatalf("expected idle, got %+v", st)
+	}
+	if a.Cycle().Completed != 0 {
+		t.Fatal("expected a stopped pomodoro not to count")
+	}
+
+	if _, st = do(t, http.MethodPost, srv.URL+"/start/long-break", token); st.Kind != app.KindLongBreak {
+		t.Fatalf("expected a long break, got %+v", st)
+	}
+
+	for _, tc := range []struct {
+		method, path string
+		code         int
+	}{
+		{http.MethodGet, "/start/pomodoro", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/status", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/start/nap", http.StatusNotFound},
+		{http.MethodPost, "/start/short-break?task=x", http.StatusBadRequest},
+		{http.MethodGet, "/nope", http.StatusNotFound},
+	} {
+		if resp, _ := do(t, tc.method, srv.URL+tc.path, token); resp.StatusCode != tc.code {
+			t.Errorf("%s %s: expected %d, got %d", tc.method, tc.path, tc.code, resp.StatusCode)
+		}
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	a, _, srv := newTestServer(t)
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/events", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+
+	events := make(chan status.Status)
+	go func() {
+		sc := bufio.NewScanner(resp.Body)
+		for sc.Scan() {
+			line := sc.Text()
+			if !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+			var st status.Status
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &st); err == nil {
+				events <- st
+			}
+		}
+		close(events)
+	}()
+
+	next := func() status.Status {
+		t.Helper()
+		select {
+		case st := <-events:
+			return st
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for an event")
+		}
+		return status.Status{}
+	}
+
+	if st := next(); st.State != app.StateIdle {
+		t.Fatalf("expected the initial idle status, got %+v", st)
+	}
+	a.StartPomodoro()
+	if st := next(); st.State != app.StatePomodoroRunning {
+		t.Fatalf("expected running, got %+v", st)
+	}
+}
+
+func TestServeStopsWithContext(t *testing.T) {
+	a, _ := apptest.NewApp(t)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- New(a, token).Serve(ctx, ln) }()
+
+	// an open event stream must not block the shutdown
+	req, _ := http.NewRequest(http.MethodGet, "http://"+ln.Addr().String()+"/events", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("server did not stop")
+	}
+}
+
+func TestLoadToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "http-token")
+
+	tok, err := LoadToken(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tok) != 64 {
+		t.Fatalf("expected a 64 character token, got %q", tok)
+	}
+	again, err := LoadToken(path)
+	if err != nil || again != tok {
+		t.Fatalf("expected the stored token back, got %q, %v", again, err)
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	fi, _ := os.Stat(path)
+	if fi.Mode().Perm() != 0o600 {
+		t.Fatalf("expected mode 0600, got %v", fi.Mode().Perm())
+	}
+	if err := os.Chmod(path, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadToken(path); err == nil {
+		t.Fatal("expected a world-readable token file to be rejected")
+	}
+}
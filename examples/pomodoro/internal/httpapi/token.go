@@ -0,0 +1,51 @@
+package httpapi
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/atomicfile"
+)
+
+// LoadToken reads the bearer token from path. When the file does not
+// exist a random token is generated and written with mode 0600. A token
+// file that other users can read is rejected.
+func LoadToken(path string) (string, error) {
+	fi, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return createToken(path)
+	}
+	if err != nil {
+		return "", err
+	}
+	// Windows does not report Unix permission bits
+	if runtime.GOOS != "windows" && fi.Mode().Perm()&0o077 != 0 {
+		return "", fmt.Errorf("httpapi: token file %s has mode %04o, want 0600", path, fi.Mode().Perm())
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return "", fmt.Errorf("httpapi: token file %s is empty", path)
+	}
+	return token, nil
+}
+
+func createToken(path string) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(b)
+	if err := atomicfile.Write(path, []byte(token+"\n"), 0o600); err != nil {
+		return "", err
+	}
+	return token, nil
+}
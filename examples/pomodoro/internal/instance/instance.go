@@ -0,0 +1,103 @@
+// Package instance makes sure only one pomodoro app runs per user. The
+// running app holds an operating system lock on a lock file, which also
+// records its process id. The system drops the lock when the process
+// exits, so the lock of a crashed process is reclaimed by the next start
+// even when its process id has been reused since.
+package instance
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// errLocked is returned by lockFile when another process holds the lock.
+var errLocked = errors.New("instance: locked by another process")
+
+// LockedError is returned by Acquire when another running process holds
+// the lock. PID is zero when the owner has not yet recorded its process
+// id.
+type LockedError struct {
+	Path string
+	PID  int
+}
+
+func (e *LockedError) Error() string {
+	if e.PID == 0 {
+		return fmt.Sprintf("another instance holds %s", e.Path)
+	}
+	return fmt.Sprintf("another instance (pid %d) holds %s", e.PID, e.Path)
+}
+
+// Lock is an acquired instance lock. It is held until Release is called
+// or the process exits.
+type Lock struct {
+	f *os.File
+}
+
+// Acquire takes the lock file at path for the current process and
+// records its process id in it. Missing parent directories are created
+// with mode 0700. It returns a *LockedError when a running process holds
+// the lock; the content of a file that is not locked, such as one left
+// by a crashed process, is ignored and replaced.
+func Acquire(path string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		owner, _ := readPID(f)
+		f.Close()
+		if errors.Is(err, errLocked) {
+			return nil, &LockedError{Path: path, PID: owner}
+		}
+		return nil, err
+	}
+	if err := writePID(f, os.Getpid()); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &Lock{f: f}, nil
+}
+
+// Release empties the lock file and drops the lock. The file itself is
+// kept: removing it could let two processes lock different files under
+// the same name.
+func (l *Lock) Release() error {
+	err := l.f.Truncate(0)
+	if cerr := l.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// writePID replaces the content of f with pid.
+func writePID(f *os.File, pid int) error {
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := f.WriteAt([]byte(strconv.Itoa(pid)+"\n"), 0); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// readPID returns the process id stored in f.
+func readPID(f *os.File) (int, error) {
+	b, err := io.ReadAll(io.NewSectionReader(f, 0, 64))
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("instance: %s does not hold a process id", f.Name())
+	}
+	return pid, nil
+}
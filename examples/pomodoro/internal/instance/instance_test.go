@@ -0,0 +1,139 @@
+package instance
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// helperEnv makes the test binary act as another instance holding the
+// lock file named by the variable, see TestHelperProcess.
+const helperEnv = "INSTANCE_TEST_HOLD_LOCK"
+
+func TestHelperProcess(t *testing.T) {
+	path := os.Getenv(helperEnv)
+	if path == "" {
+		return
+	}
+	if _, err := Acquire(path); err != nil {
+		os.Stdout.WriteString("error: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+	os.Stdout.WriteString("locked\n")
+	// hold the lock until killed
+	select {}
+}
+
+func readContent(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestAcquireRecordsPID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run", "pomodoro.pid")
+
+	l, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	if got, want := readContent(t, path), strconv.Itoa(os.Getpid())+"\n"; got != want {
+		t.Fatalf("lock holds %q, want %q", got, want)
+	}
+	if err := l.Release(); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if got := readContent(t, path); got != "" {
+		t.Fatalf("lock file holds %q after release, want it empty", got)
+	}
+}
+
+func TestAcquireFailsWhileLocked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pomodoro.pid")
+	l, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("first acquire: %v", err)
+	}
+
+	_, err = Acquire(path)
+	var locked *LockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("second acquire: got %v, want *LockedError", err)
+	}
+	if locked.PID != os.Getpid() {
+		t.Fatalf("LockedError.PID = %d, want %d", locked.PID, os.Getpid())
+	}
+
+	if err := l.Release(); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	l, err = Acquire(path)
+	if err != nil {
+		t.Fatalf("acquire after release: %v", err)
+	}
+	l.Release()
+}
+
+func TestAcquireIgnoresUnlockedFiles(t *testing.T) {
+	for name, content := range map[string]string{
+		// the process id of a crashed instance, reused by a running process
+		"reused pid": strconv.Itoa(os.Getppid()) + "\n",
+		"garbage":    "not a pid",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "pomodoro.pid")
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			l, err := Acquire(path)
+			if err != nil {
+				t.Fatalf("acquire over an unlocked file: %v", err)
+			}
+			defer l.Release()
+			if got, want := readContent(t, path), strconv.Itoa(os.Getpid())+"\n"; got != want {
+				t.Fatalf("lock holds %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestLockOfCrashedProcessIsReclaimed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pomodoro.pid")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+	cmd.Env = append(os.Environ(), helperEnv+"="+path)
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer cmd.Process.Kill()
+	if line, _ := bufio.NewReader(out).ReadString('\n'); line != "locked\n" {
+		t.Fatalf("helper process: %q", line)
+	}
+
+	_, err = Acquire(path)
+	var locked *LockedError
+	if !errors.As(err, &locked) || locked.PID != cmd.Process.Pid {
+		t.Fatalf("acquire while the helper runs: got %v, want the helper's pid %d", err, cmd.Process.Pid)
+	}
+
+	// the helper dies without releasing the lock
+	if err := cmd.Process.Kill(); err != nil {
+		t.Fatal(err)
+	}
+	cmd.Wait()
+	l, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("acquire after the owner crashed: %v", err)
+	}
+	l.Release()
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !windows
+
+package instance
+
+import (
+	"errors"
+	"os"
+)
+
+// lockFile is not supported on this platform.
+func lockFile(f *os.File) error {
+	return errors.New("instance: file locks are not supported on this platform")
+}
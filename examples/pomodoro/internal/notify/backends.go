@@ -0,0 +1,198 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Backend names accepted by `New`.
+const (
+	// BackendAuto picks the first backend available on this system.
+	BackendAuto       = "auto"
+	BackendNotifySend = "notify-send"
+	BackendDBus       = "dbus"
+	BackendOSAScript  = "osascript"
+	BackendBell       = "bell"
+	// BackendNone shows no notifications.
+	BackendNone = "none"
+)
+
+// appName is how notifications are attributed on the desktop.
+const appName = "Pomodoro"
+
+// waitDelay bounds waiting for the output of a killed command whose
+// children keep it open.
+const waitDelay = time.Second
+
+// backends maps backend names to their constructors.
+var backends = map[string]func() Notifier{
+	BackendNotifySend: NewNotifySend,
+	BackendDBus:       NewDBus,
+	BackendOSAScript:  NewOSAScript,
+	BackendBell:       func() Notifier { return NewBell(os.Stderr) },
+	BackendNone:       func() Notifier { return none{} },
+}
+
+// Backends returns the names accepted by `New`, sorted, including
+// "auto".
+func Backends() []string {
+	names := []string{BackendAuto}
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New returns the backend registered under name. "auto" chooses
+// `osascript` on macOS, otherwise `notify-send` or `gdbus`, whichever is
+// installed first, and the terminal bell when none is.
+func New(name string) (Notifier, error) {
+	if name == BackendAuto {
+		name = Detect(runtime.GOOS, exec.LookPath)
+	}
+	newNotifier, ok := backends[name]
+	if !ok {
+		return nil, fmt.Errorf("notify: unknown backend %q (want one of %s)", name, strings.Join(Backends(), ", "))
+	}
+	return newNotifier(), nil
+}
+
+// Detect returns the backend "auto" stands for on goos, where lookPath
+// finds installed programs.
+func Detect(goos string, lookPath func(string) (string, error)) string {
+	candidates := []struct{ backend, program string }{
+		{BackendNotifySend, "notify-send"},
+		{BackendDBus, "gdbus"},
+	}
+	switch goos {
+	case "darwin":
+		candidates = []struct{ backend, program string }{{BackendOSAScript, "osascript"}}
+	case "windows":
+		candidates = nil
+	}
+	for _, c := range candidates {
+		if _, err := lookPath(c.program); err == nil {
+			return c.backend
+		}
+	}
+	return BackendBell
+}
+
+// command shows notifications by running a program.
+type command struct {
+	name string
+	args func(n Notification) []string
+}
+
+// NewNotifySend returns a Notifier running libnotify's `notify-send`.
+func NewNotifySend() Notifier {
+	return &command{name: "notify-send", args: notifySendArgs}
+}
+
+func notifySendArgs(n Notification) []string {
+	return []string{"--app-name=" + appName, "--", n.Title, n.Body}
+}
+
+// NewDBus returns a Notifier calling the freedesktop notification
+// service on the session bus through `gdbus`, which ships with GLib.
+func NewDBus() Notifier {
+	return &command{name: "gdbus", args: dbusArgs}
+}
+
+func dbusArgs(n Notification) []string {
+	return []string{
+		"call", "--session",
+		"--dest", "org.freedesktop.Notifications",
+		"--object-path", "/org/freedesktop/Notifications",
+		"--method", "org.freedesktop.Notifications.Notify",
+		"--",
+		// app name, replaced id, icon, summary, body, actions, hints
+		// and the expiry, where -1 leaves it to the server.
+		gvariantString(appName), "0", gvariantString(""),
+		gvariantString(n.Title), gvariantString(n.Body),
+		"[]", "{}", "-1",
+	}
+}
+
+// gvariantString quotes s as a GVariant text format string, which is how
+// gdbus reads its arguments.
+func gvariantString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case !unicode.IsPrint(r):
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// NewOSAScript returns a Notifier using AppleScript's `display
+// notification` through `osascript`. Title and body are passed as
+// arguments of the script, so they need no quoting.
+func NewOSAScript() Notifier {
+	return &command{name: "osascript", args: osascriptArgs}
+}
+
+func osascriptArgs(n Notification) []string {
+	return []string{
+		"-e", "on run argv",
+		"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+		"-e", "end run",
+		n.Title, n.Body,
+	}
+}
+
+// Notify runs the program and reports its output when it fails.
+func (c *command) Notify(ctx context.Context, n Notification) error {
+	cmd := exec.CommandContext(ctx, c.name, c.args(n)...)
+	cmd.WaitDelay = waitDelay
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("notify: %s: %w: %s", c.name, err, msg)
+		}
+		return fmt.Errorf("notify: %s: %w", c.name, err)
+	}
+	return nil
+}
+
+// Bell rings the terminal bell, the fallback when no desktop
+// notification service is available.
+type Bell struct {
+	w io.Writer
+}
+
+// NewBell returns a Bell writing to w, usually the terminal on stderr.
+func NewBell(w io.Writer) *Bell { return &Bell{w: w} }
+
+// Notify writes the BEL character; the text of n is not shown.
+func (b *Bell) Notify(ctx context.Context, n Notification) error {
+	_, err := io.WriteString(b.w, "\a")
+	return err
+}
+
+// none discards notifications.
+type none struct{}
+
+func (none) Notify(context.Context, Notification) error { return nil }
@@ -0,0 +1,152 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	installed := func(programs ...string) func(string) (string, error) {
+		return func(name string) (string, error) {
+			for _, p := range programs {
+				if p == name {
+					return "/usr/bin/" + name, nil
+				}
+			}
+			return "", errors.New("not found")
+		}
+	}
+	for _, tc := range []struct {
+		goos      string
+		installed []string
+		want      string
+	}{
+		{"linux", []string{"notify-send", "gdbus"}, BackendNotifySend},
+		{"linux", []string{"gdbus"}, BackendDBus},
+		{"freebsd", []string{"notify-send"}, BackendNotifySend},
+		{"linux", nil, BackendBell},
+		{"darwin", []string{"osascript", "notify-send"}, BackendOSAScript},
+		{"darwin", nil, BackendBell},
+		{"windows", []string{"notify-send"}, BackendBell},
+	} {
+		if got := Detect(tc.goos, installed(tc.installed...)); got != tc.want {
+			t.Errorf("Detect(%s, %v) = %s, want %s", tc.goos, tc.installed, got, tc.want)
+		}
+	}
+}
+
+func TestNewRejectsUnknownBackends(t *testing.T) {
+	for _, name := range Backends() {
+		if _, err := New(name); err != nil {
+			t.Errorf("New(%q): %v", name, err)
+		}
+	}
+	_, err := New("growl")
+	if err == nil || !strings.Contains(err.Error(), "auto, bell, dbus, none, notify-send, osascript") {
+		t.Errorf("New(growl): err = %v", err)
+	}
+}
+
+// fakeProgram installs an executable shell script named name on a PATH
+// of its own. The script writes its arguments, one per line, to the
+// returned file.
+func fakeProgram(t *testing.T, name, script string) (args string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake programs in these tests are shell scripts")
+	}
+	dir := t.TempDir()
+	args = filepath.Join(t.TempDir(), "args")
+	content := "#!/bin/sh\nfor a in \"$@\"; do echo \"$a\"; done > \"" + args + "\"\n" + script + "\n"
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+	return args
+}
+
+func readArgs(t *testing.T, path string) []string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("program did not run: %v", err)
+	}
+	return strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+}
+
+func TestCommandBackends(t *testing.T) {
+	n := Notification{Title: `Done "now"`, Body: "-take a break"}
+	for _, tc := range []struct {
+		backend, program string
+		want             []string
+	}{
+		{BackendNotifySend, "notify-send", []string{"--app-name=Pomodoro", "--", `Done "now"`, "-take a break"}},
+		{BackendDBus, "gdbus", []string{
+			"call", "--session",
+			"--dest", "org.freedesktop.Notifications",
+			"--object-path", "/org/freedesktop/Notifications",
+			"--method", "org.freedesktop.Notifications.Notify",
+			"--", `"Pomodoro"`, "0", `""`, `"Done \"now\""`, `"-take a break"`, "[]", "{}", "-1",
+		}},
+		{BackendOSAScript, "osascript", []string{
+			"-e", "on run argv",
+			"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+			"-e", "end run",
+			`Done "now"`, "-take a break",
+		}},
+	} {
+		t.Run(tc.backend, func(t *testing.T) {
+			args := fakeProgram(t, tc.program, "")
+			notifier, err := New(tc.backend)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := notifier.Notify(context.Background(), n); err != nil {
+				t.Fatal(err)
+			}
+			got := readArgs(t, args)
+			if strings.Join(got, "\n") != strings.Join(tc.want, "\n") {
+				t.Errorf("arguments = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCommandFailureReportsOutput(t *testing.T) {
+	fakeProgram(t, "notify-send", "echo 'cannot connect to the session bus' >&2; exit 1")
+	err := NewNotifySend().Notify(context.Background(), Notification{Title: "t"})
+	if err == nil || !strings.Contains(err.Error(), "notify-send: exit status 1: cannot connect to the session bus") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestGVariantString(t *testing.T) {
+	for in, want := range map[string]string{
+		"":                `""`,
+		"plain":           `"plain"`,
+		`a "quoted" \ ok`: `"a \"quoted\" \\ ok"`,
+		"two\nlines\ttab": `"two\nlines\ttab"`,
+		"bell\a":          `"bell\u0007"`,
+		"café 🍅":          `"café 🍅"`,
+	} {
+		if got := gvariantString(in); got != want {
+			t.Errorf("gvariantString(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestBell(t *testing.T) {
+	var b bytes.Buffer
+	if err := NewBell(&b).Notify(context.Background(), Notification{Title: "t", Body: "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "\a" {
+		t.Errorf("bell wrote %q", b.String())
+	}
+}
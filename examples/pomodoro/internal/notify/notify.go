@@ -0,0 +1,191 @@
+// Package notify announces completed sessions with desktop notifications,
+// so the end of a pomodoro is noticed even when the tray is out of sight.
+//
+// A `Notifier` shows a single notification. The backends run the tools
+// the desktop already has: `notify-send` or `gdbus` talking to the
+// freedesktop notification service on the session bus on Linux and the
+// BSDs, `osascript` on macOS, and a terminal bell where neither exists.
+// `Attach` connects a Notifier to an app.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+)
+
+const (
+	// DefaultTimeout bounds showing a single notification.
+	DefaultTimeout = 10 * time.Second
+	// queueSize bounds the notifications waiting to be shown; further
+	// notifications are dropped so the app is never blocked.
+	queueSize = 16
+)
+
+// Default texts of the notifications, as templates over `Data`.
+const (
+	DefaultPomodoroTitle = "Pomodoro complete"
+	DefaultPomodoroBody  = "{{if .Task}}{{.Task}}: {{end}}{{.Minutes}} minutes of focus done. Time for a break."
+	DefaultBreakTitle    = "Break over"
+	DefaultBreakBody     = "Ready for the next pomodoro?"
+)
+
+// Notification is a message for the user.
+type Notification struct {
+	Title string
+	Body  string
+}
+
+// Notifier shows notifications. Notify returns once the notification was
+// handed to the desktop, or with an error when that failed or ctx ended.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Data is what the title and body templates of a `Message` render.
+type Data struct {
+	// Kind is the kind of the completed session.
+	Kind app.Kind
+	// Task is the label of the completed session, if any.
+	Task string
+	// Minutes is the planned length of the session.
+	Minutes int
+	// Cycle is the progress through the pomodoro cycle after the
+	// session, for example "2/4".
+	Cycle app.Cycle
+}
+
+// Message renders the notification for a completed session.
+type Message struct {
+	Title *template.Template
+	Body  *template.Template
+}
+
+// ParseMessage parses the title and body templates of a Message.
+func ParseMessage(title, body string) (Message, error) {
+	t, err := template.New("title").Parse(title)
+	if err != nil {
+		return Message{}, fmt.Errorf("notify: title: %w", err)
+	}
+	b, err := template.New("body").Parse(body)
+	if err != nil {
+		return Message{}, fmt.Errorf("notify: body: %w", err)
+	}
+	return Message{Title: t, Body: b}, nil
+}
+
+// mustParseMessage parses built-in templates.
+func mustParseMessage(title, body string) Message {
+	m, err := ParseMessage(title, body)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Render executes the templates of m with d.
+func (m Message) Render(d Data) (Notification, error) {
+	var title, body bytes.Buffer
+	if err := m.Title.Execute(&title, d); err != nil {
+		return Notification{}, fmt.Errorf("notify: title: %w", err)
+	}
+	if err := m.Body.Execute(&body, d); err != nil {
+		return Notification{}, fmt.Errorf("notify: body: %w", err)
+	}
+	return Notification{Title: title.String(), Body: body.String()}, nil
+}
+
+// Options configure `Attach`. Zero fields use the defaults.
+type Options struct {
+	// Pomodoro is shown when a pomodoro completes and Break when a
+	// short or long break completes.
+	Pomodoro Message
+	Break    Message
+	// Timeout bounds each notification.
+	Timeout time.Duration
+	// Logger receives failed notifications.
+	Logger *log.Logger
+}
+
+// Attach shows a notification through n whenever a session of a
+// completes, until the returned function is called, which then waits for
+// the notification being shown. Sessions that are skipped, replaced or
+// ended by quitting are not announced. Notifications are shown in the
+// background, in order, so a slow desktop never delays the app.
+func Attach(a app.App, n Notifier, o Options) (detach func()) {
+	if o.Pomodoro.Title == nil || o.Pomodoro.Body == nil {
+		o.Pomodoro = mustParseMessage(DefaultPomodoroTitle, DefaultPomodoroBody)
+	}
+	if o.Break.Title == nil || o.Break.Body == nil {
+		o.Break = mustParseMessage(DefaultBreakTitle, DefaultBreakBody)
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultTimeout
+	}
+	if o.Logger == nil {
+		o.Logger = log.Default()
+	}
+
+	queue := make(chan Notification, queueSize)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for msg := range queue {
+			ctx, cancel := context.WithTimeout(context.Background(), o.Timeout)
+			if err := n.Notify(ctx, msg); err != nil {
+				o.Logger.Printf("notification %q failed: %v", msg.Title, err)
+			}
+			cancel()
+		}
+	}()
+
+	var mu sync.Mutex
+	stopped := false
+	unsubscribe := a.SubscribeEvents(func(e app.Event) {
+		if e.Reason != app.ReasonCompleted || e.Kind == "" {
+			return
+		}
+		m := o.Break
+		if e.Kind == app.KindPomodoro {
+			m = o.Pomodoro
+		}
+		msg, err := m.Render(Data{
+			Kind:    e.Kind,
+			Task:    e.Task,
+			Minutes: int((e.Planned + time.Minute/2) / time.Minute),
+			Cycle:   a.Cycle(),
+		})
+		if err != nil {
+			o.Logger.Printf("notification skipped: %v", err)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if stopped {
+			return
+		}
+		select {
+		case queue <- msg:
+		default:
+			o.Logger.Printf("notification %q skipped: too many pending notifications", msg.Title)
+		}
+	})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			unsubscribe()
+			mu.Lock()
+			stopped = true
+			close(queue)
+			mu.Unlock()
+			<-done
+		})
+	}
+}
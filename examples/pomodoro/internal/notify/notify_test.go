@@ -0,0 +1,132 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/apptest"
+)
+
+func TestCompletionsAreNotified(t *testing.T) {
+	a, c := apptest.NewApp(t)
+	r := &Recorder{}
+	detach := Attach(a, r, Options{})
+
+	a.StartPomodoroFor("ABC-123")
+	c.Advance(25 * time.Minute)
+	a.StartShortBreak()
+	c.Advance(5 * time.Minute)
+	a.StartPomodoro()
+	a.Skip()
+	a.StartPomodoro()
+	a.StartLongBreak() // replaces the pomodoro
+	a.CompleteNow()
+	detach()
+
+	want := []Notification{
+		{Title: "Pomodoro complete", Body: "ABC-123: 25 minutes of focus done. Time for a break."},
+		{Title: "Break over", Body: "Ready for the next pomodoro?"},
+		{Title: "Break over", Body: "Ready for the next pomodoro?"},
+	}
+	got := r.Notifications()
+	if len(got) != len(want) {
+		t.Fatalf("notifications = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("notification %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestCustomMessages(t *testing.T) {
+	a, c := apptest.NewApp(t)
+	r := &Recorder{}
+	pomodoro, err := ParseMessage("{{.Kind}} {{.Cycle}}", "{{.Minutes}}m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	brk, err := ParseMessage("{{.Kind}} over", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	detach := Attach(a, r, Options{Pomodoro: pomodoro, Break: brk})
+	a.StartPomodoro()
+	c.Advance(25 * time.Minute)
+	a.StartBreak()
+	c.Advance(5 * time.Minute)
+	detach()
+
+	got := r.Notifications()
+	if len(got) != 2 || got[0] != (Notification{Title: "pomodoro 1/4", Body: "25m"}) || got[1] != (Notification{Title: "short-break over"}) {
+		t.Fatalf("notifications = %+v", got)
+	}
+}
+
+func TestParseMessageRejectsInvalidTemplates(t *testing.T) {
+	if _, err := ParseMessage("{{.Kind", "body"); err == nil || !strings.Contains(err.Error(), "title") {
+		t.Errorf("invalid title: err = %v", err)
+	}
+	if _, err := ParseMessage("title", "{{end}}"); err == nil || !strings.Contains(err.Error(), "body") {
+		t.Errorf("invalid body: err = %v", err)
+	}
+}
+
+func TestFailedNotificationsAreLogged(t *testing.T) {
+	a, _ := apptest.NewApp(t)
+	logs := &apptest.SyncBuffer{}
+	r := &Recorder{Err: errors.New("no notification service")}
+	detach := Attach(a, r, Options{Logger: log.New(logs, "", 0)})
+	a.StartPomodoro()
+	a.CompleteNow()
+	detach()
+
+	if want := `notification "Pomodoro complete" failed: no notification service`; !strings.Contains(logs.String(), want) {
+		t.Errorf("log = %q, want %q", logs.String(), want)
+	}
+}
+
+// blockingNotifier blocks every notification until release is closed.
+type blockingNotifier struct {
+	release chan struct{}
+}
+
+func (b blockingNotifier) Notify(ctx context.Context, n Notification) error {
+	select {
+	case <-b.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestSlowNotifierDoesNotBlockTheApp(t *testing.T) {
+	a, _ := apptest.NewApp(t)
+	logs := &apptest.SyncBuffer{}
+	n := blockingNotifier{release: make(chan struct{})}
+	detach := Attach(a, n, Options{Logger: log.New(logs, "", 0)})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 2*queueSize; i++ {
+			a.StartPomodoro()
+			a.CompleteNow()
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("app blocked by a slow notifier")
+	}
+	close(n.release)
+	detach()
+
+	if !strings.Contains(logs.String(), "too many pending notifications") {
+		t.Errorf("log = %q, want dropped notifications reported", logs.String())
+	}
+}
@@ -0,0 +1,30 @@
+package notify
+
+import (
+	"context"
+	"sync"
+)
+
+// Recorder is a Notifier that keeps the notifications instead of showing
+// them. Useful for tests of code that sends notifications.
+type Recorder struct {
+	mu            sync.Mutex
+	notifications []Notification
+	// Err, when set, is returned by Notify after recording.
+	Err error
+}
+
+// Notify records n.
+func (r *Recorder) Notify(ctx context.Context, n Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.notifications = append(r.notifications, n)
+	return r.Err
+}
+
+// Notifications returns the recorded notifications in order.
+func (r *Recorder) Notifications() []Notification {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Notification(nil), r.notifications...)
+}
@@ -0,0 +1,96 @@
+// Package paths locates the files the pomodoro app keeps on disk. All
+// files live below a single per-user directory so they are easy to find,
+// inspect and remove.
+package paths
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// appDir is the name of the per-user application directory.
+const appDir = "pomodoro"
+
+// ConfigDir returns the per-user directory for pomodoro files, for example
+// `~/Library/Application Support/pomodoro` on macOS or
+// `~/.config/pomodoro` on Linux. The directory is not created.
+func ConfigDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appDir), nil
+}
+
+// HistoryFile returns the path of the session history log.
+func HistoryFile() (string, error) {
+	return inConfigDir("history.jsonl")
+}
+
+// StateFile returns the path of the file holding the active session.
+func StateFile() (string, error) {
+	return inConfigDir("session.json")
+}
+
+// TokenFile returns the path of the bearer token of the HTTP control API.
+func TokenFile() (string, error) {
+	return inConfigDir("http-token")
+}
+
+// WebhookQueueFile returns the path of the queue of undelivered
+// webhook payloads.
+func WebhookQueueFile() (string, error) {
+	return inConfigDir("webhook-queue.json")
+}
+
+// HooksDir returns the directory of the user's hook scripts.
+func HooksDir() (string, error) {
+	return inConfigDir("hooks.d")
+}
+
+// RuntimeDir returns the per-user directory for files that only matter
+// while the app runs, such as the control socket: `$XDG_RUNTIME_DIR/pomodoro`
+// when that variable is set, the config directory otherwise. The directory
+// is not created.
+func RuntimeDir() (string, error) {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, appDir), nil
+	}
+	return ConfigDir()
+}
+
+// SocketFile returns the path of the control socket of the running app.
+func SocketFile() (string, error) {
+	dir, err := RuntimeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "control.sock"), nil
+}
+
+// StatusFile returns the path of the file describing the session of the
+// running app.
+func StatusFile() (string, error) {
+	dir, err := RuntimeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "status.json"), nil
+}
+
+// LockFile returns the path of the lock file held by the running app.
+func LockFile() (string, error) {
+	dir, err := RuntimeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "pomodoro.pid"), nil
+}
+
+func inConfigDir(name string) (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
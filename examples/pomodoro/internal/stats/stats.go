@@ -0,0 +1,238 @@
+// Package stats aggregates the session history into daily, weekly and
+// monthly totals. Everything is computed by pure functions over
+// `history.Record` values, so results do not depend on the running app.
+package stats
+
+import (
+	"sort"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/history"
+)
+
+// kindPomodoro is the history kind of pomodoros; every other kind is a
+// break.
+const kindPomodoro = "pomodoro"
+
+// TaskTotal sums the completed pomodoros spent on one task.
+type TaskTotal struct {
+	Task      string
+	Pomodoros int
+	Focus     time.Duration
+}
+
+// Totals aggregates the sessions that started within one period.
+type Totals struct {
+	// Start is the beginning of the period in the report's location. It
+	// is zero for the all-time totals.
+	Start time.Time
+	// Pomodoros counts completed pomodoros and Abandoned those that were
+	// cancelled or replaced by another session before their end.
+	Pomodoros int
+	Abandoned int
+	// Focus and Break are the time spent in pomodoros and breaks.
+	Focus time.Duration
+	Break time.Duration
+	// Tasks lists labelled work, most focus time first.
+	Tasks []TaskTotal
+}
+
+// CompletionRate returns the share of finished pomodoros that were
+// completed rather than abandoned, or 0 when there are none.
+func (t Totals) CompletionRate() float64 {
+	n := t.Pomodoros + t.Abandoned
+	if n == 0 {
+		return 0
+	}
+	return float64(t.Pomodoros) / float64(n)
+}
+
+// Report holds the totals per period, each list ordered by period start.
+// Periods without sessions are omitted.
+type Report struct {
+	Days   []Totals
+	Weeks  []Totals
+	Months []Totals
+	All    Totals
+	// LongestStreak is the highest number of consecutive days with at
+	// least one completed pomodoro.
+	LongestStreak int
+	loc           *time.Location
+}
+
+// Day returns the totals of the day containing t, which are empty when
+// nothing happened that day.
+func (r Report) Day(t time.Time) Totals {
+	return find(r.Days, DayStart(t, r.loc))
+}
+
+// Week returns the totals of the week containing t.
+func (r Report) Week(t time.Time) Totals {
+	return find(r.Weeks, WeekStart(t, r.loc))
+}
+
+// Month returns the totals of the month containing t.
+func (r Report) Month(t time.Time) Totals {
+	return find(r.Months, MonthStart(t, r.loc))
+}
+
+func find(periods []Totals, start time.Time) Totals {
+	for _, p := range periods {
+		if p.Start.Equal(start) {
+			return p
+		}
+	}
+	return Totals{Start: start}
+}
+
+// DayStart returns local midnight of the day containing t in loc. Days
+// are 23 or 25 hours long when daylight saving time changes.
+func DayStart(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// WeekStart returns the start of the ISO week (Monday) containing t.
+func WeekStart(t time.Time, loc *time.Location) time.Time {
+	d := DayStart(t, loc)
+	offset := (int(d.Weekday()) + 6) % 7
+	return time.Date(d.Year(), d.Month(), d.Day()-offset, 0, 0, 0, 0, loc)
+}
+
+// MonthStart returns the first day of the month containing t.
+func MonthStart(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+}
+
+// Compute aggregates records into a report. Sessions count towards the
+// periods in which they started, in loc. A session that was shut down and
+// later restored appears twice in the log with the same kind and start;
+// only its last record counts. Expired sessions, which ran out while the
+// app was not running, are ignored.
+func Compute(records []history.Record, loc *time.Location) Report {
+	if loc == nil {
+		loc = time.Local
+	}
+	days := make(map[time.Time]*accumulator)
+	weeks := make(map[time.Time]*accumulator)
+	months := make(map[time.Time]*accumulator)
+	all := &accumulator{}
+	streakDays := make(map[time.Time]bool)
+
+	for _, r := range history.Dedupe(records) {
+		if r.Outcome == history.OutcomeExpired {
+			continue
+		}
+		for _, a := range []*accumulator{
+			bucket(days, DayStart(r.Start, loc)),
+			bucket(weeks, WeekStart(r.Start, loc)),
+			bucket(months, MonthStart(r.Start, loc)),
+			all,
+		} {
+			a.add(r)
+		}
+		if r.Kind == kindPomodoro && r.Outcome == history.OutcomeCompleted {
+			streakDays[DayStart(r.Start, loc)] = true
+		}
+	}
+
+	return Report{
+		Days:          collect(days),
+		Weeks:         collect(weeks),
+		Months:        collect(months),
+		All:           all.totals(time.Time{}),
+		LongestStreak: longestStreak(streakDays),
+		loc:           loc,
+	}
+}
+
+// accumulator sums records of one period.
+type accumulator struct {
+	t     Totals
+	tasks map[string]*TaskTotal
+}
+
+func bucket(m map[time.Time]*accumulator, start time.Time) *accumulator {
+	a, ok := m[start]
+	if !ok {
+		a = &accumulator{}
+		m[start] = a
+	}
+	return a
+}
+
+func (a *accumulator) add(r history.Record) {
+	elapsed := r.Elapsed
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	if r.Kind != kindPomodoro {
+		a.t.Break += elapsed
+		return
+	}
+	a.t.Focus += elapsed
+	switch r.Outcome {
+	case history.OutcomeCompleted:
+		a.t.Pomodoros++
+	case history.OutcomeCancelled, history.OutcomeSuperseded:
+		a.t.Abandoned++
+	}
+	if r.Label == "" || r.Outcome != history.OutcomeCompleted {
+		return
+	}
+	if a.tasks == nil {
+		a.tasks = make(map[string]*TaskTotal)
+	}
+	tt, ok := a.tasks[r.Label]
+	if !ok {
+		tt = &TaskTotal{Task: r.Label}
+		a.tasks[r.Label] = tt
+	}
+	tt.Pomodoros++
+	tt.Focus += elapsed
+}
+
+func (a *accumulator) totals(start time.Time) Totals {
+	t := a.t
+	t.Start = start
+	for _, tt := range a.tasks {
+		t.Tasks = append(t.Tasks, *tt)
+	}
+	sort.Slice(t.Tasks, func(i, j int) bool {
+		if t.Tasks[i].Focus != t.Tasks[j].Focus {
+			return t.Tasks[i].Focus > t.Tasks[j].Focus
+		}
+		return t.Tasks[i].Task < t.Tasks[j].Task
+	})
+	return t
+}
+
+func collect(m map[time.Time]*accumulator) []Totals {
+	out := make([]Totals, 0, len(m))
+	for start, a := range m {
+		out = append(out, a.totals(start))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	return out
+}
+
+// longestStreak returns the longest run of consecutive days in days,
+// which are local midnights.
+func longestStreak(days map[time.Time]bool) int {
+	best := 0
+	for d := range days {
+		// only count runs from their first day
+		if days[d.AddDate(0, 0, -1)] {
+			continue
+		}
+		n := 1
+		for next := d.AddDate(0, 0, 1); days[next]; next = next.AddDate(0, 0, 1) {
+			n++
+		}
+		if n > best {
+			best = n
+		}
+	}
+	return best
+}
@@ -0,0 +1,185 @@
+package stats
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/history"
+)
+
+func berlin(t *testing.T) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+// rec returns a record of kind starting at start and lasting d.
+func rec(kind string, start time.Time, d time.Duration, outcome history.Outcome, label string) history.Record {
+	return history.Record{Kind: kind, Start: start, End: start.Add(d), Planned: d, Elapsed: d, Outcome: outcome, Label: label}
+}
+
+func TestComputeTotals(t *testing.T) {
+	loc := berlin(t)
+	day := time.Date(2025, 12, 3, 9, 0, 0, 0, loc) // a Wednesday
+
+	tests := []struct {
+		name    string
+		records []history.Record
+		want    Totals
+		rate    float64
+	}{
+		{
+			name: "empty",
+			want: Totals{},
+		},
+		{
+			name: "completed and abandoned pomodoros with breaks",
+			records: []history.Record{
+				rec("pomodoro", day, 25*time.Minute, history.OutcomeCompleted, "ABC-1"),
+				rec("short-break", day.Add(25*time.Minute), 5*time.Minute, history.OutcomeCompleted, ""),
+				rec("pomodoro", day.Add(30*time.Minute), 10*time.Minute, history.OutcomeCancelled, "ABC-1"),
+				rec("pomodoro", day.Add(40*time.Minute), 3*time.Minute, history.OutcomeSuperseded, ""),
+				rec("pomodoro", day.Add(time.Hour), 25*time.Minute, history.OutcomeCompleted, "XYZ-2"),
+				rec("long-break", day.Add(90*time.Minute), 25*time.Minute, history.OutcomeShutdown, ""),
+			},
+			want: Totals{
+				Pomodoros: 2,
+				Abandoned: 2,
+				Focus:     63 * time.Minute,
+				Break:     30 * time.Minute,
+				Tasks: []TaskTotal{
+					{Task: "ABC-1", Pomodoros: 1, Focus: 25 * time.Minute},
+					{Task: "XYZ-2", Pomodoros: 1, Focus: 25 * time.Minute},
+				},
+			},
+			rate: 0.5,
+		},
+		{
+			name: "shutdown and restored session counts once",
+			records: []history.Record{
+				rec("pomodoro", day, 10*time.Minute, history.OutcomeShutdown, ""),
+				rec("pomodoro", day, 25*time.Minute, history.OutcomeCompleted, ""),
+			},
+			want: Totals{Pomodoros: 1, Focus: 25 * time.Minute},
+			rate: 1,
+		},
+		{
+			name: "expired sessions are ignored",
+			records: []history.Record{
+				rec("pomodoro", day, 25*time.Minute, history.OutcomeExpired, ""),
+			},
+			want: Totals{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Compute(tt.records, loc)
+			got := r.All
+			if got.Pomodoros != tt.want.Pomodoros || got.Abandoned != tt.want.Abandoned ||
+				got.Focus != tt.want.Focus || got.Break != tt.want.Break {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+			if len(got.Tasks) != len(tt.want.Tasks) {
+				t.Fatalf("got tasks %+v, want %+v", got.Tasks, tt.want.Tasks)
+			}
+			for i := range got.Tasks {
+				if got.Tasks[i] != tt.want.Tasks[i] {
+					t.Fatalf("task %d: got %+v, want %+v", i, got.Tasks[i], tt.want.Tasks[i])
+				}
+			}
+			if rate := got.CompletionRate(); rate != tt.rate {
+				t.Fatalf("got completion rate %v, want %v", rate, tt.rate)
+			}
+			if d := r.Day(day); d.Pomodoros != tt.want.Pomodoros || d.Focus != tt.want.Focus {
+				t.Fatalf("expected the day to match the totals, got %+v", d)
+			}
+		})
+	}
+}
+
+func TestComputePeriodsRespectLocalTime(t *testing.T) {
+	loc := berlin(t)
+
+	tests := []struct {
+		name  string
+		start time.Time
+		day   time.Time
+		week  time.Time
+		month time.Time
+	}{
+		{
+			// 23:30 UTC on the 29th is already the 30th in Berlin
+			name:  "after local midnight, before UTC midnight",
+			start: time.Date(2025, 3, 29, 23, 30, 0, 0, time.UTC),
+			day:   time.Date(2025, 3, 30, 0, 0, 0, 0, loc),
+			week:  time.Date(2025, 3, 24, 0, 0, 0, 0, loc),
+			month: time.Date(2025, 3, 1, 0, 0, 0, 0, loc),
+		},
+		{
+			// the day clocks fall back has 25 hours
+			name:  "late on a 25 hour day",
+			start: time.Date(2025, 10, 26, 23, 50, 0, 0, loc),
+			day:   time.Date(2025, 10, 26, 0, 0, 0, 0, loc),
+			week:  time.Date(2025, 10, 20, 0, 0, 0, 0, loc),
+			month: time.Date(2025, 10, 1, 0, 0, 0, 0, loc),
+		},
+		{
+			name:  "week spanning a month boundary",
+			start: time.Date(2025, 11, 2, 8, 0, 0, 0, loc),
+			day:   time.Date(2025, 11, 2, 0, 0, 0, 0, loc),
+			week:  time.Date(2025, 10, 27, 0, 0, 0, 0, loc),
+			month: time.Date(2025, 11, 1, 0, 0, 0, 0, loc),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Compute([]history.Record{
+				rec("pomodoro", tt.start, 25*time.Minute, history.OutcomeCompleted, ""),
+			}, loc)
+			if len(r.Days) != 1 || !r.Days[0].Start.Equal(tt.day) {
+				t.Fatalf("got days %+v, want start %v", r.Days, tt.day)
+			}
+			if len(r.Weeks) != 1 || !r.Weeks[0].Start.Equal(tt.week) {
+				t.Fatalf("got weeks %+v, want start %v", r.Weeks, tt.week)
+			}
+			if len(r.Months) != 1 || !r.Months[0].Start.Equal(tt.month) {
+				t.Fatalf("got months %+v, want start %v", r.Months, tt.month)
+			}
+		})
+	}
+}
+
+func TestLongestStreak(t *testing.T) {
+	loc := berlin(t)
+	at := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2025, month, day, hour, 0, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name string
+		days []time.Time
+		want int
+	}{
+		{name: "none", want: 0},
+		{name: "single day, several pomodoros", days: []time.Time{at(3, 1, 9), at(3, 1, 14)}, want: 1},
+		{name: "gap breaks the streak", days: []time.Time{at(3, 1, 9), at(3, 2, 9), at(3, 4, 9), at(3, 5, 9), at(3, 6, 9)}, want: 3},
+		{name: "across the DST change", days: []time.Time{at(3, 29, 23), at(3, 30, 0), at(3, 31, 9)}, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var recs []history.Record
+			for _, d := range tt.days {
+				recs = append(recs, rec("pomodoro", d, 25*time.Minute, history.OutcomeCompleted, ""))
+			}
+			// abandoned pomodoros do not extend a streak
+			recs = append(recs, rec("pomodoro", at(3, 7, 9), time.Minute, history.OutcomeCancelled, ""))
+			if got := Compute(recs, loc).LongestStreak; got != tt.want {
+				t.Fatalf("got streak %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
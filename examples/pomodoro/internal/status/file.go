@@ -0,0 +1,97 @@
+package status
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+	"github.com/co0p/4dc/examples/pomodoro/internal/atomicfile"
+	"github.com/co0p/4dc/examples/pomodoro/internal/clock"
+)
+
+// FileInterval is how often `PublishFile` refreshes the file between
+// state changes. Readers that need the exact remaining time use the end
+// time.
+const FileInterval = 30 * time.Second
+
+// WriteFile replaces the file at path with st as JSON. Readers see either
+// the old or the new content, never a partial file.
+func WriteFile(path string, st Status) error {
+	b, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	return atomicfile.Write(path, append(b, '\n'), 0o600)
+}
+
+// PublishFile keeps the file at path describing the session of a, for
+// tools that can read files but not talk to the app. The file is
+// rewritten on every state change, when the session is extended and
+// every interval of c, and removed when a shuts down. Write failures are
+// logged. The returned function stops publishing and removes the file.
+func PublishFile(a app.App, path string, c clock.Clock, interval time.Duration) (stop func()) {
+	var (
+		mu      sync.Mutex
+		removed bool
+	)
+	write := func() {
+		if err := WriteFile(path, Of(a)); err != nil {
+			log.Printf("status file: write failed: %v", err)
+		}
+	}
+	remove := func() {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("status file: %v", err)
+		}
+	}
+
+	mu.Lock()
+	write()
+	mu.Unlock()
+	unsubscribe := a.SubscribeEvents(func(e app.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case e.Reason == app.ReasonShutdown:
+			removed = true
+			remove()
+		case e.IsStateChange() || e.Reason == app.ReasonExtended:
+			removed = false
+			write()
+		}
+	})
+
+	ticker := c.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C():
+				mu.Lock()
+				if !removed {
+					write()
+				}
+				mu.Unlock()
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			unsubscribe()
+			ticker.Stop()
+			close(done)
+			mu.Lock()
+			defer mu.Unlock()
+			removed = true
+			remove()
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+	"github.com/co0p/4dc/examples/pomodoro/internal/apptest"
+	"github.com/co0p/4dc/examples/pomodoro/internal/clock"
+)
+
+func readStatusFile(t *testing.T, path string) Status {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var st Status
+	if err := json.Unmarshal(b, &st); err != nil {
+		t.Fatalf("invalid status file %q: %v", b, err)
+	}
+	return st
+}
+
+// handClock is a clock whose tickers deliver only the ticks sent on
+// ticks. The channel is unbuffered, so a send returns once the reader
+// took the tick, and a second send once it finished handling the first.
+type handClock struct {
+	clock.Clock
+	ticks chan time.Time
+}
+
+func (h handClock) NewTicker(time.Duration) clock.Ticker { return handTicker(h) }
+
+type handTicker handClock
+
+func (h handTicker) C() <-chan time.Time { return h.ticks }
+func (h handTicker) Stop()               {}
+
+// tick delivers a tick of h and returns once it was handled.
+func (h handClock) tick() {
+	h.ticks <- h.Now()
+	h.ticks <- h.Now()
+}
+
+func TestPublishFileFollowsTheSession(t *testing.T) {
+	a, fc := apptest.NewApp(t)
+	c := handClock{Clock: fc, ticks: make(chan time.Time)}
+	path := filepath.Join(t.TempDir(), "run", "status.json")
+
+	stop := PublishFile(a, path, c, FileInterval)
+	defer stop()
+	if st := readStatusFile(t, path); st.State != app.StateIdle {
+		t.Fatalf("initial file shows %s, want idle", st.State)
+	}
+
+	a.StartPomodoroFor("ABC-123")
+	st := readStatusFile(t, path)
+	if st.State != app.StatePomodoroRunning || st.Task != "ABC-123" || st.RemainingSeconds != 25*60 {
+		t.Fatalf("unexpected status after start: %+v", st)
+	}
+	if st.End == nil || !st.End.Equal(time.Date(2025, 12, 5, 9, 25, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected end %v", st.End)
+	}
+
+	// the tick keeps the remaining time roughly current
+	fc.Advance(FileInterval)
+	c.tick()
+	if st := readStatusFile(t, path); st.RemainingSeconds != 25*60-int(FileInterval/time.Second) {
+		t.Fatalf("unexpected remaining time after a tick: %+v", st)
+	}
+
+	if err := a.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("status file still exists after shutdown: %v", err)
+	}
+	// ticks after shutdown do not bring the file back
+	fc.Advance(FileInterval)
+	c.tick()
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("status file rewritten after shutdown: %v", err)
+	}
+}
+
+func TestPublishFileStopRemovesFile(t *testing.T) {
+	a, c := apptest.NewApp(t)
+	path := filepath.Join(t.TempDir(), "status.json")
+
+	stop := PublishFile(a, path, c, FileInterval)
+	stop()
+	stop()
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("status file still exists after stop: %v", err)
+	}
+	a.StartPomodoro()
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("status file written after stop: %v", err)
+	}
+}
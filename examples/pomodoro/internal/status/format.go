@@ -0,0 +1,144 @@
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+)
+
+// Formatter renders a Status as a single line.
+type Formatter func(Status) (string, error)
+
+// Names of the formats understood by `ParseFormat`. A format can also be
+// given as "template=" followed by a text/template, see `Template`.
+const (
+	FormatText   = "text"
+	FormatJSON   = "json"
+	FormatWaybar = "waybar"
+	FormatTmux   = "tmux"
+)
+
+// templatePrefix introduces a template in a format spec.
+const templatePrefix = "template="
+
+// ParseFormat returns the formatter named by spec: "text", "json",
+// "waybar", "tmux" or "template=TEXT".
+func ParseFormat(spec string) (Formatter, error) {
+	switch spec {
+	case FormatText:
+		return plain(Text), nil
+	case FormatJSON:
+		return JSON, nil
+	case FormatWaybar:
+		return Waybar, nil
+	case FormatTmux:
+		return plain(Tmux), nil
+	}
+	if strings.HasPrefix(spec, templatePrefix) {
+		return Template(strings.TrimPrefix(spec, templatePrefix))
+	}
+	return nil, fmt.Errorf("unknown format %q (want text, json, waybar, tmux or template=...)", spec)
+}
+
+func plain(f func(Status) string) Formatter {
+	return func(s Status) (string, error) { return f(s), nil }
+}
+
+// Text renders s for people, for example
+// "pomodoro 12m3s left (ABC-123), cycle 1/4" or "idle, cycle 1/4".
+func Text(s Status) string {
+	if s.State == app.StateIdle {
+		return fmt.Sprintf("idle, cycle %s", s.Cycle)
+	}
+	var b strings.Builder
+	b.WriteString(string(s.Kind))
+	if s.State == app.StatePaused {
+		b.WriteString(" paused,")
+	}
+	fmt.Fprintf(&b, " %s left", s.Remaining)
+	if s.Task != "" {
+		fmt.Fprintf(&b, " (%s)", s.Task)
+	}
+	fmt.Fprintf(&b, ", cycle %s", s.Cycle)
+	return b.String()
+}
+
+// JSON renders s as a JSON object on one line.
+func JSON(s Status) (string, error) {
+	b, err := json.Marshal(s)
+	return string(b), err
+}
+
+// waybarOutput is the JSON a waybar custom module with
+// `"return-type": "json"` reads.
+type waybarOutput struct {
+	Text       string `json:"text"`
+	Alt        string `json:"alt"`
+	Tooltip    string `json:"tooltip"`
+	Class      string `json:"class"`
+	Percentage int    `json:"percentage"`
+}
+
+// Waybar renders s for a waybar custom module. The text is the countdown,
+// empty while idle so the module can hide; alt and class are the kind of
+// session, or "paused" and "idle", for icons and CSS.
+func Waybar(s Status) (string, error) {
+	out := waybarOutput{
+		Alt:        barClass(s),
+		Tooltip:    Text(s),
+		Class:      barClass(s),
+		Percentage: s.Progress,
+	}
+	if s.State != app.StateIdle {
+		out.Text = s.Countdown()
+	}
+	b, err := json.Marshal(out)
+	return string(b), err
+}
+
+// Tmux renders s for the tmux status line, coloured by the kind of
+// session: red for a pomodoro, green for a break, yellow while paused.
+// It is empty while idle.
+func Tmux(s Status) string {
+	colour, marker := "red", "●"
+	switch {
+	case s.State == app.StateIdle:
+		return ""
+	case s.State == app.StatePaused:
+		colour, marker = "yellow", "⏸"
+	case s.Kind != app.KindPomodoro:
+		colour = "green"
+	}
+	return fmt.Sprintf("#[fg=%s]%s %s#[default]", colour, marker, s.Countdown())
+}
+
+// barClass names the session for status bar styling.
+func barClass(s Status) string {
+	switch s.State {
+	case app.StateIdle:
+		return "idle"
+	case app.StatePaused:
+		return "paused"
+	}
+	return string(s.Kind)
+}
+
+// Template returns a formatter executing text as a text/template with
+// the Status, for example "{{.Countdown}} {{.Task}}". Besides the fields
+// of Status, templates can use .Minutes and .Countdown.
+func Template(text string) (Formatter, error) {
+	tmpl, err := template.New("status").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return func(s Status) (string, error) {
+		var b strings.Builder
+		if err := tmpl.Execute(&b, s); err != nil {
+			return "", err
+		}
+		return b.String(), nil
+	}, nil
+}
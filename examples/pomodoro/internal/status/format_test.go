@@ -0,0 +1,90 @@
+package status
+
+import (
+	"testing"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+	"github.com/co0p/4dc/examples/pomodoro/internal/apptest"
+)
+
+func running() Status {
+	return Status{
+		State:            app.StatePomodoroRunning,
+		Kind:             app.KindPomodoro,
+		Remaining:        "12m5s",
+		RemainingSeconds: 12*60 + 5,
+		Progress:         51,
+		Task:             "ABC-123",
+		Cycle:            Cycle{Completed: 1, Length: 4},
+	}
+}
+
+func TestFormats(t *testing.T) {
+	idle := Status{State: app.StateIdle, Remaining: "0s", Cycle: Cycle{Completed: 2, Length: 4}}
+	paused := running()
+	paused.State = app.StatePaused
+	brk := running()
+	brk.State, brk.Kind, brk.Task = app.StateBreakRunning, app.KindShortBreak, ""
+
+	tests := []struct {
+		spec string
+		st   Status
+		want string
+	}{
+		{"text", running(), "pomodoro 12m5s left (ABC-123), cycle 1/4"},
+		{"text", paused, "pomodoro paused, 12m5s left (ABC-123), cycle 1/4"},
+		{"text", idle, "idle, cycle 2/4"},
+		{"json", idle, `{"state":"Idle","remaining":"0s","remaining_seconds":0,"progress":0,"cycle":{"completed":2,"length":4}}`},
+		{"waybar", running(), `{"text":"12:05","alt":"pomodoro","tooltip":"pomodoro 12m5s left (ABC-123), cycle 1/4","class":"pomodoro","percentage":51}`},
+		{"waybar", idle, `{"text":"","alt":"idle","tooltip":"idle, cycle 2/4","class":"idle","percentage":0}`},
+		{"tmux", running(), "#[fg=red]● 12:05#[default]"},
+		{"tmux", paused, "#[fg=yellow]⏸ 12:05#[default]"},
+		{"tmux", brk, "#[fg=green]● 12:05#[default]"},
+		{"tmux", idle, ""},
+		{"template={{.Minutes}}m {{.Task}} {{.Cycle}}", running(), "12m ABC-123 1/4"},
+		{"template={{.Remaining}}", running(), "12m5s"},
+	}
+	for _, tt := range tests {
+		f, err := ParseFormat(tt.spec)
+		if err != nil {
+			t.Fatalf("ParseFormat(%q): %v", tt.spec, err)
+		}
+		got, err := f(tt.st)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.spec, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s of %s:\n got %s\nwant %s", tt.spec, tt.st.State, got, tt.want)
+		}
+	}
+}
+
+func TestParseFormatErrors(t *testing.T) {
+	for _, spec := range []string{"", "xml", "template={{.Nope"} {
+		if _, err := ParseFormat(spec); err == nil {
+			t.Errorf("ParseFormat(%q) succeeded, want an error", spec)
+		}
+	}
+	f, err := ParseFormat("template={{.Nope}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f(running()); err == nil {
+		t.Error("expected executing an unknown field to fail")
+	}
+}
+
+func TestOfReportsProgress(t *testing.T) {
+	a, c := apptest.NewApp(t)
+	a.StartPomodoroFor("ABC-123")
+	c.Advance(5 * time.Minute)
+
+	st := Of(a)
+	if st.Progress != 20 || st.Countdown() != "20:00" || st.Minutes() != 20 || st.Task != "ABC-123" {
+		t.Fatalf("unexpected status %+v", st)
+	}
+	if st.End == nil || !st.End.Equal(time.Date(2025, 12, 5, 9, 25, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected end %v", st.End)
+	}
+}
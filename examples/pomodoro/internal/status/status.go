@@ -0,0 +1,70 @@
+// Package status describes the current session of the app in a form
+// shared by the control interfaces, such as the HTTP API and the control
+// socket, and renders it for status bars, shell prompts and the tray
+// title.
+package status
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+)
+
+// Status is a point-in-time description of the timer.
+type Status struct {
+	State app.State `json:"state"`
+	Kind  app.Kind  `json:"kind,omitempty"`
+	// Remaining is a Go duration string; RemainingSeconds the same in
+	// whole seconds.
+	Remaining        string     `json:"remaining"`
+	RemainingSeconds int        `json:"remaining_seconds"`
+	End              *time.Time `json:"end,omitempty"`
+	// Progress is the elapsed share of the session in percent.
+	Progress int    `json:"progress"`
+	Task     string `json:"task,omitempty"`
+	Cycle    Cycle  `json:"cycle"`
+}
+
+// Minutes returns the whole minutes remaining.
+func (s Status) Minutes() int {
+	return s.RemainingSeconds / 60
+}
+
+// Countdown returns the remaining time as minutes and seconds, for
+// example "04:05".
+func (s Status) Countdown() string {
+	return fmt.Sprintf("%02d:%02d", s.RemainingSeconds/60, s.RemainingSeconds%60)
+}
+
+// Cycle is the progress through the pomodoro cycle.
+type Cycle struct {
+	Completed int `json:"completed"`
+	Length    int `json:"length"`
+}
+
+// String returns the progress as "completed/length", for example "1/4".
+func (c Cycle) String() string {
+	return fmt.Sprintf("%d/%d", c.Completed, c.Length)
+}
+
+// Of describes the current session of a.
+func Of(a app.App) Status {
+	snap := a.Snapshot()
+	s := Status{
+		State:            snap.State,
+		Kind:             snap.Kind,
+		Remaining:        snap.Remaining.Truncate(time.Second).String(),
+		RemainingSeconds: int(snap.Remaining / time.Second),
+		Task:             snap.Task,
+		Cycle:            Cycle{Completed: snap.Cycle.Completed, Length: snap.Cycle.Length},
+	}
+	if snap.State != app.StateIdle && snap.Planned > 0 && snap.Remaining <= snap.Planned {
+		s.Progress = int((snap.Planned - snap.Remaining) * 100 / snap.Planned)
+	}
+	if !snap.End.IsZero() {
+		end := snap.End
+		s.End = &end
+	}
+	return s
+}
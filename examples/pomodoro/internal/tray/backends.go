@@ -0,0 +1,51 @@
+package tray
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+)
+
+// DefaultBackend is the backend used when none is chosen.
+const DefaultBackend = "systray"
+
+// Options carries the inputs of the backends; each backend uses the
+// fields it needs.
+type Options struct {
+	// Icon and Title configure the system tray.
+	Icon  []byte
+	Title TitleSettings
+	// In and Out are the terminal of the terminal front-end.
+	In  io.Reader
+	Out io.Writer
+}
+
+// backends maps backend names to their constructors.
+var backends = map[string]func(a app.App, o Options) Tray{
+	"systray":  func(a app.App, o Options) Tray { return NewSystray(a, o.Icon, o.Title) },
+	"terminal": func(a app.App, o Options) Tray { return NewTerminal(a, o.In, o.Out) },
+	"headless": func(a app.App, o Options) Tray { return NewHeadless(a) },
+}
+
+// Backends returns the names accepted by `New`, sorted.
+func Backends() []string {
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New returns the backend registered under name: "systray", "terminal"
+// or "headless".
+func New(name string, a app.App, o Options) (Tray, error) {
+	newTray, ok := backends[name]
+	if !ok {
+		return nil, fmt.Errorf("tray: unknown backend %q (want one of %s)", name, strings.Join(Backends(), ", "))
+	}
+	return newTray(a, o), nil
+}
@@ -0,0 +1,64 @@
+package tray
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+)
+
+type headlessTray struct {
+	app    app.App
+	logger *log.Logger
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// NewHeadless returns a Tray without any user interface, for machines
+// without a display. It logs every state change with the end time of
+// the session and the cycle progress; the timer is controlled through
+// the control socket or the HTTP API.
+func NewHeadless(a app.App) Tray {
+	return newHeadless(a, log.Default())
+}
+
+func newHeadless(a app.App, l *log.Logger) *headlessTray {
+	return &headlessTray{app: a, logger: l, closed: make(chan struct{})}
+}
+
+// Run logs transitions until ctx is done or Close is called.
+func (h *headlessTray) Run(ctx context.Context) error {
+	h.logger.Printf("running headless: %s", headlessSummary(h.app))
+	unsubscribe := h.app.SubscribeEvents(func(e app.Event) {
+		if e.IsStateChange() {
+			h.logger.Printf("headless: %s", headlessSummary(h.app))
+		}
+	})
+	defer unsubscribe()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-h.closed:
+		return nil
+	}
+}
+
+// Close requests Run to return. It is idempotent.
+func (h *headlessTray) Close() error {
+	h.closeOnce.Do(func() { close(h.closed) })
+	return nil
+}
+
+// headlessSummary describes the session of a in one line, for example
+// "Pomodoro: ABC-123 · cycle 1/4 · ends 09:25:00".
+func headlessSummary(a app.App) string {
+	s := Tooltip(a)
+	snap := a.Snapshot()
+	if !snap.End.IsZero() {
+		s += " · ends " + snap.End.Format("15:04:05")
+	}
+	return s
+}
@@ -0,0 +1,79 @@
+package tray
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+	"github.com/co0p/4dc/examples/pomodoro/internal/apptest"
+)
+
+func TestHeadlessLogsTransitions(t *testing.T) {
+	a, _ := apptest.NewApp(t)
+	out := &apptest.SyncBuffer{}
+	h := newHeadless(a, log.New(out, "", 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- h.Run(ctx) }()
+	apptest.Eventually(t, "startup line", func() bool { return strings.Contains(out.String(), "running headless") })
+
+	a.StartPomodoro()
+	a.Extend(ExtendStep)
+	a.Pause()
+	apptest.Eventually(t, "paused line", func() bool { return strings.Contains(out.String(), "headless: Paused: Pomodoro") })
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run returned %v, want context.Canceled", err)
+	}
+
+	want := []string{
+		"running headless: Idle · cycle 0/4",
+		"headless: Pomodoro · cycle 0/4 · ends 09:25:00",
+		"headless: Paused: Pomodoro · cycle 0/4",
+	}
+	if got := strings.Split(strings.TrimSpace(out.String()), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("log lines:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestHeadlessCloseStopsRun(t *testing.T) {
+	a, _ := app.NewWithOptions()
+	h := newHeadless(a, log.New(&bytes.Buffer{}, "", 0))
+
+	done := make(chan error, 1)
+	go func() { done <- h.Run(context.Background()) }()
+	h.Close()
+	h.Close()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run returned %v after Close, want nil", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after Close")
+	}
+}
+
+func TestNewSelectsBackend(t *testing.T) {
+	a, _ := app.NewWithOptions()
+	for _, name := range Backends() {
+		if tr, err := New(name, a, Options{}); err != nil || tr == nil {
+			t.Fatalf("New(%q) = %v, %v", name, tr, err)
+		}
+	}
+	if tr, _ := New("headless", a, Options{}); tr == nil {
+		t.Fatal(`New("headless") returned nil`)
+	} else if _, ok := tr.(*headlessTray); !ok {
+		t.Fatal(`New("headless") did not return the headless backend`)
+	}
+	if _, err := New("gtk", a, Options{}); err == nil || !strings.Contains(err.Error(), "headless, systray, terminal") {
+		t.Fatalf(`New("gtk") error = %v, want one listing the backends`, err)
+	}
+}
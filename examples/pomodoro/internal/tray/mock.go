@@ -32,7 +32,9 @@ func (m *MockTray) Close() error {
 }
 
 // Trigger simulates a user clicking a menu item by name.
-// Supported names: "Pomodoro", "Short Break", "Long Break", "Break", "Quit".
+// Supported names: "Pomodoro", "Short Break", "Long Break", "Break",
+// "Pause", "Resume", "+5 min", "Finish Now", "Skip",
+// "Interrupted (internal)", "Interrupted (external)", "Quit".
 func (m *MockTray) Trigger(name string) {
 	switch name {
 	case "Pomodoro":
@@ -42,8 +44,22 @@ func (m *MockTray) Trigger(name string) {
 	case "Long Break":
 		m.App.StartLongBreak()
 	case "Break":
-		// kept for compatibility: treat as short break
-		m.App.StartShortBreak()
+		// next break of the cycle: short, or long once the cycle is done
+		m.App.StartBreak()
+	case "Pause":
+		m.App.Pause()
+	case "Resume":
+		m.App.Resume()
+	case "+5 min":
+		m.App.Extend(ExtendStep)
+	case "Finish Now":
+		m.App.CompleteNow()
+	case "Skip":
+		m.App.Skip()
+	case "Interrupted (internal)":
+		_ = m.App.RecordInterruption(app.InterruptionInternal)
+	case "Interrupted (external)":
+		_ = m.App.RecordInterruption(app.InterruptionExternal)
 	case "Quit":
 		_ = m.App.Shutdown(context.Background())
 	}
@@ -1,15 +1,15 @@
 package tray
 
 import (
-	"context"
 	"testing"
 	"time"
 
 	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+	"github.com/co0p/4dc/examples/pomodoro/internal/apptest"
 )
 
 func TestMockTrayTriggersAppActions(t *testing.T) {
-	a := app.New(50*time.Millisecond, 20*time.Millisecond)
+	a, _ := apptest.NewApp(t)
 	mt := NewMockTray(a)
 
 	// Start pomodoro via mock trigger
@@ -26,35 +26,105 @@ func TestMockTrayTriggersAppActions(t *testing.T) {
 
 	// Trigger quit; Shutdown should set idle
 	mt.Trigger("Quit")
-	// allow a tiny moment for shutdown hooks
-	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
-	defer cancel()
-	select {
-	case <-time.After(10 * time.Millisecond):
-	case <-ctx.Done():
-		t.Fatalf("shutdown did not complete in time")
-	}
 	if a.State() != app.StateIdle {
 		t.Fatalf("expected idle after quit, got %s", a.State())
 	}
 }
 
 func TestMockTrayShortAndLongBreakTriggers(t *testing.T) {
-	a := app.New(50*time.Millisecond, 10*time.Millisecond)
+	a, c := apptest.NewApp(t)
 	mt := NewMockTray(a)
 
 	// Trigger short break explicitly
 	mt.Trigger("Short Break")
-	if a.State() != app.StateBreakRunning {
-		t.Fatalf("expected short break running, got %s", a.State())
+	if a.State() != app.StateBreakRunning || a.Kind() != app.KindShortBreak {
+		t.Fatalf("expected short break running, got %s/%q", a.State(), a.Kind())
 	}
 
-	// Wait for short break to finish
-	time.Sleep(20 * time.Millisecond)
+	// Let the short break finish
+	c.Advance(app.DefaultShortBreak)
+	if a.State() != app.StateIdle {
+		t.Fatalf("expected idle after the short break, got %s", a.State())
+	}
 
 	// Trigger long break explicitly
 	mt.Trigger("Long Break")
-	if a.State() != app.StateBreakRunning {
-		t.Fatalf("expected long break running, got %s", a.State())
+	if a.State() != app.StateBreakRunning || a.Kind() != app.KindLongBreak {
+		t.Fatalf("expected long break running, got %s/%q", a.State(), a.Kind())
+	}
+}
+
+func TestMockTrayPauseAndResumeTriggers(t *testing.T) {
+	a, c := apptest.NewApp(t)
+	mt := NewMockTray(a)
+
+	mt.Trigger("Pomodoro")
+	c.Advance(5 * time.Minute)
+	mt.Trigger("Pause")
+	if a.State() != app.StatePaused {
+		t.Fatalf("expected paused, got %s", a.State())
+	}
+	if a.Kind() != app.KindPomodoro {
+		t.Fatalf("expected paused pomodoro, got %q", a.Kind())
+	}
+	// time does not run out while paused
+	c.Advance(time.Hour)
+	if a.State() != app.StatePaused || a.Remaining() != 20*time.Minute {
+		t.Fatalf("expected paused with 20m left, got %s with %v", a.State(), a.Remaining())
+	}
+
+	mt.Trigger("Resume")
+	if a.State() != app.StatePomodoroRunning {
+		t.Fatalf("expected pomodoro running after resume, got %s", a.State())
+	}
+	c.Advance(20 * time.Minute)
+	if a.State() != app.StateIdle || a.Cycle().Completed != 1 {
+		t.Fatalf("expected the resumed pomodoro to complete, got %s %s", a.State(), a.Cycle())
+	}
+}
+
+func TestMockTrayExtendTrigger(t *testing.T) {
+	a, _ := apptest.NewApp(t)
+	mt := NewMockTray(a)
+
+	mt.Trigger("Pomodoro")
+	mt.Trigger("+5 min")
+	if rem := a.Remaining(); rem != app.DefaultPomodoro+5*time.Minute {
+		t.Fatalf("expected the session to be extended to 30m, got %v", rem)
+	}
+	if a.State() != app.StatePomodoroRunning {
+		t.Fatalf("expected pomodoro still running, got %s", a.State())
+	}
+}
+
+func TestMockTrayFinishAndSkipTriggers(t *testing.T) {
+	a, _ := apptest.NewApp(t)
+	mt := NewMockTray(a)
+
+	mt.Trigger("Pomodoro")
+	mt.Trigger("Finish Now")
+	if a.State() != app.StateIdle || a.Cycle().Completed != 1 {
+		t.Fatalf("expected a counted pomodoro, got %s %s", a.State(), a.Cycle())
+	}
+
+	mt.Trigger("Pomodoro")
+	mt.Trigger("Skip")
+	if a.State() != app.StateIdle || a.Cycle().Completed != 1 {
+		t.Fatalf("expected the skipped pomodoro not to count, got %s %s", a.State(), a.Cycle())
+	}
+}
+
+func TestMockTrayInterruptionTriggers(t *testing.T) {
+	a, _ := apptest.NewApp(t)
+	mt := NewMockTray(a)
+
+	// ignored while idle
+	mt.Trigger("Interrupted (internal)")
+	mt.Trigger("Pomodoro")
+	mt.Trigger("Interrupted (internal)")
+	mt.Trigger("Interrupted (external)")
+	mt.Trigger("Interrupted (external)")
+	if got, want := a.Interruptions(), (app.Interruptions{Internal: 1, External: 2}); got != want {
+		t.Fatalf("expected %v, got %v", want, got)
 	}
 }
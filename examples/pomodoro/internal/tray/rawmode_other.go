@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd
+
+package tray
+
+import "errors"
+
+// makeRaw is not supported on this platform; keys are read line by line
+// and take effect after Enter.
+func makeRaw(fd int) (restore func(), err error) {
+	return nil, errors.New("tray: raw terminal mode not supported")
+}
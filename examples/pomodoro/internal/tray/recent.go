@@ -0,0 +1,56 @@
+package tray
+
+import "sync"
+
+// recentTasksMax is the number of labels in the "Recent tasks" submenu.
+const recentTasksMax = 5
+
+// recentTasks keeps the most recently used task labels, newest first and
+// without duplicates.
+type recentTasks struct {
+	mu     sync.Mutex
+	max    int
+	labels []string
+}
+
+func newRecentTasks(max int) *recentTasks {
+	return &recentTasks{max: max}
+}
+
+// Add moves label to the front of the list. Empty labels are ignored.
+// It reports whether the list changed.
+func (r *recentTasks) Add(label string) bool {
+	if label == "" {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.labels) > 0 && r.labels[0] == label {
+		return false
+	}
+	labels := []string{label}
+	for _, l := range r.labels {
+		if l != label && len(labels) < r.max {
+			labels = append(labels, l)
+		}
+	}
+	r.labels = labels
+	return true
+}
+
+// At returns the label at index i, or "" when there is none.
+func (r *recentTasks) At(i int) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if i < 0 || i >= len(r.labels) {
+		return ""
+	}
+	return r.labels[i]
+}
+
+// List returns a copy of the labels, newest first.
+func (r *recentTasks) List() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.labels...)
+}
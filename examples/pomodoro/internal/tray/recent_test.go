@@ -0,0 +1,22 @@
+package tray
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRecentTasksNewestFirstWithoutDuplicates(t *testing.T) {
+	r := newRecentTasks(3)
+	for _, l := range []string{"a", "b", "", "a", "c", "d"} {
+		r.Add(l)
+	}
+	if got, want := r.List(), []string{"d", "c", "a"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if r.Add("d") {
+		t.Fatal("expected re-adding the newest label to be a no-op")
+	}
+	if r.At(1) != "c" || r.At(5) != "" {
+		t.Fatalf("unexpected At results %q %q", r.At(1), r.At(5))
+	}
+}
@@ -3,19 +3,26 @@ package tray
 import (
 	"context"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+	"github.com/co0p/4dc/examples/pomodoro/internal/clock"
 	"github.com/getlantern/systray"
 )
 
 type systrayImpl struct {
 	app  app.App
 	icon []byte
+
+	mu      sync.Mutex
+	title   TitleSettings
+	updater *TitleUpdater
+	recent  *recentTasks
 }
 
-func newSystrayImpl(a app.App, icon []byte) Tray {
-	return &systrayImpl{app: a, icon: icon}
+func newSystrayImpl(a app.App, icon []byte, title TitleSettings) Tray {
+	return &systrayImpl{app: a, icon: icon, title: title, recent: newRecentTasks(recentTasksMax)}
 }
 
 func (s *systrayImpl) Run(ctx context.Context) error {
@@ -32,14 +39,47 @@ func (s *systrayImpl) Run(ctx context.Context) error {
 	// directly in this goroutine so callers that invoke Run from main()
 	// satisfy that requirement.
 	var u *TitleUpdater
+	var unsubEvents func()
 	systray.Run(func() {
 		if len(s.icon) > 0 {
 			systray.SetIcon(s.icon)
 		}
 		mPom := systray.AddMenuItem("Pomodoro", "Start Pomodoro")
+		mRecent := systray.AddMenuItem("Recent tasks", "Start a pomodoro on a recently used task")
+		recentItems := make([]*systray.MenuItem, recentTasksMax)
+		for i := range recentItems {
+			recentItems[i] = mRecent.AddSubMenuItem("", "Start a pomodoro on this task")
+		}
+		refreshRecent := func() {
+			labels := s.recent.List()
+			for i, item := range recentItems {
+				if i < len(labels) {
+					item.SetTitle(labels[i])
+					item.Show()
+				} else {
+					item.Hide()
+				}
+			}
+			if len(labels) == 0 {
+				mRecent.Disable()
+			} else {
+				mRecent.Enable()
+			}
+		}
+		refreshRecent()
+		mBreak := systray.AddMenuItem("Break", "Start the next break of the cycle")
 		mShort := systray.AddMenuItem("Short Break", "Start Short Break")
 		mLong := systray.AddMenuItem("Long Break", "Start Long Break")
 		systray.AddSeparator()
+		mPause := systray.AddMenuItem("Pause", "Pause the running session")
+		mResume := systray.AddMenuItem("Resume", "Resume the paused session")
+		mExtend := systray.AddMenuItem("+5 min", "Add five minutes to the current session")
+		mFinish := systray.AddMenuItem("Finish Now", "End the session now and count it as done")
+		mSkip := systray.AddMenuItem("Skip", "Abandon the session without counting it")
+		systray.AddSeparator()
+		mIntInternal := systray.AddMenuItem("Interrupted (internal)", "Record an interruption that came from yourself")
+		mIntExternal := systray.AddMenuItem("Interrupted (external)", "Record an interruption by someone else")
+		systray.AddSeparator()
 		mQuit := systray.AddMenuItem("Quit", "Quit the app")
 
 		// listen for menu clicks
@@ -49,6 +89,23 @@ func (s *systrayImpl) Run(ctx context.Context) error {
 				s.app.StartPomodoro()
 			}
 		}()
+		for i, item := range recentItems {
+			i, item := i, item
+			go func() {
+				for range item.ClickedCh {
+					if task := s.recent.At(i); task != "" {
+						log.Printf("action=StartPomodoroFor task=%q", task)
+						s.app.StartPomodoroFor(task)
+					}
+				}
+			}()
+		}
+		go func() {
+			for range mBreak.ClickedCh {
+				log.Printf("action=StartBreak cycle=%s", s.app.Cycle())
+				s.app.StartBreak()
+			}
+		}()
 		go func() {
 			for range mShort.ClickedCh {
 				log.Println("action=StartShortBreak")
@@ -61,6 +118,52 @@ func (s *systrayImpl) Run(ctx context.Context) error {
 				s.app.StartLongBreak()
 			}
 		}()
+		go func() {
+			for range mPause.ClickedCh {
+				log.Println("action=Pause")
+				s.app.Pause()
+			}
+		}()
+		go func() {
+			for range mResume.ClickedCh {
+				log.Println("action=Resume")
+				s.app.Resume()
+			}
+		}()
+		go func() {
+			for range mExtend.ClickedCh {
+				log.Printf("action=Extend by=%s", ExtendStep)
+				s.app.Extend(ExtendStep)
+			}
+		}()
+		go func() {
+			for range mFinish.ClickedCh {
+				log.Println("action=CompleteNow")
+				s.app.CompleteNow()
+			}
+		}()
+		go func() {
+			for range mSkip.ClickedCh {
+				log.Println("action=Skip")
+				s.app.Skip()
+			}
+		}()
+		go func() {
+			for range mIntInternal.ClickedCh {
+				log.Println("action=RecordInterruption kind=internal")
+				if err := s.app.RecordInterruption(app.InterruptionInternal); err != nil {
+					log.Printf("interruption ignored: %v", err)
+				}
+			}
+		}()
+		go func() {
+			for range mIntExternal.ClickedCh {
+				log.Println("action=RecordInterruption kind=external")
+				if err := s.app.RecordInterruption(app.InterruptionExternal); err != nil {
+					log.Printf("interruption ignored: %v", err)
+				}
+			}
+		}()
 		go func() {
 			for range mQuit.ClickedCh {
 				log.Println("action=Quit")
@@ -72,6 +175,15 @@ func (s *systrayImpl) Run(ctx context.Context) error {
 			}
 		}()
 
+		// keep the tooltip and the recent tasks in sync with the session
+		systray.SetTooltip(Tooltip(s.app))
+		unsubEvents = s.app.SubscribeEvents(func(e app.Event) {
+			systray.SetTooltip(Tooltip(s.app))
+			if e.Reason == app.ReasonStarted && s.recent.Add(e.Task) {
+				refreshRecent()
+			}
+		})
+
 		// Start title updater. It subscribes to app state changes and
 		// periodically queries Remaining() to update the tray title.
 		//
@@ -86,16 +198,21 @@ func (s *systrayImpl) Run(ctx context.Context) error {
 		updaterCancel = cancel
 		setTitle := func(t string) { systray.SetTitle(t) }
 		clearTitle := func() { systray.SetTitle("") }
-		newTicker := func(d time.Duration) (<-chan time.Time, func()) {
-			t := time.NewTicker(d)
-			return t.C, func() { t.Stop() }
+		u = NewTitleUpdater(s.app, setTitle, clearTitle, ClockTicker(clock.Real()))
+		s.mu.Lock()
+		if err := u.Apply(s.title); err != nil {
+			log.Printf("title settings ignored: %v", err)
 		}
-		u = NewTitleUpdater(s.app, setTitle, clearTitle, newTicker)
+		s.updater = u
+		s.mu.Unlock()
 		go u.Run(updaterCtx)
 	}, func() {
 		if updaterCancel != nil {
 			updaterCancel()
 		}
+		if unsubEvents != nil {
+			unsubEvents()
+		}
 		// If we constructed a TitleUpdater above, ensure it is stopped so
 		// it detaches subscriptions and clears the title.
 		// Note: the updater was run in a goroutine; Stop() is safe to call
@@ -110,6 +227,28 @@ func (s *systrayImpl) Run(ctx context.Context) error {
 	return ctx.Err()
 }
 
+// ApplyTitleSettings changes the title format and refresh interval. When
+// the tray is running the title updater picks them up immediately.
+func (s *systrayImpl) ApplyTitleSettings(ts TitleSettings) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.updater != nil {
+		if err := s.updater.Apply(ts); err != nil {
+			return err
+		}
+	}
+	s.title = ts
+	return nil
+}
+
+// SeedRecentTasks fills the "Recent tasks" submenu, newest label first.
+// It must be called before Run.
+func (s *systrayImpl) SeedRecentTasks(labels []string) {
+	for i := len(labels) - 1; i >= 0; i-- {
+		s.recent.Add(labels[i])
+	}
+}
+
 func (s *systrayImpl) Close() error {
 	systray.Quit()
 	return nil
@@ -0,0 +1,242 @@
+package tray
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+	"github.com/co0p/4dc/examples/pomodoro/internal/clock"
+)
+
+const (
+	// terminalRefreshInterval is the cadence at which the countdown is
+	// redrawn.
+	terminalRefreshInterval = time.Second
+	// progressWidth is the number of cells of the progress bar.
+	progressWidth = 20
+	// terminalHelp lists the key bindings below the status line.
+	terminalHelp = "p pomodoro · s short break · l long break · space pause/resume · q quit"
+)
+
+// ANSI escape sequences used by the terminal front-end.
+const (
+	ansiClearLine  = "\r\x1b[2K"
+	ansiHideCursor = "\x1b[?25l"
+	ansiShowCursor = "\x1b[?25h"
+	ansiBold       = "\x1b[1m"
+	ansiReset      = "\x1b[0m"
+)
+
+type terminalTray struct {
+	app           app.App
+	in            io.Reader
+	out           io.Writer
+	tickerFactory func(d time.Duration) (<-chan time.Time, func())
+
+	closeOnce sync.Once
+	closed    chan struct{}
+
+	// mu serialises writes to out.
+	mu sync.Mutex
+	// line is the status line currently shown.
+	line string
+}
+
+// NewTerminal returns a Tray that draws the session on out as a single
+// status line, with a live countdown, progress bar, state and cycle
+// count, and reads single-key commands from in: p starts a pomodoro, s a
+// short break, l a long break, space pauses or resumes, q (or Ctrl-C)
+// quits. When in is a terminal it is put into raw mode while Run is
+// active so keys act without Enter.
+func NewTerminal(a app.App, in io.Reader, out io.Writer) Tray {
+	return newTerminal(a, in, out, ClockTicker(clock.Real()))
+}
+
+func newTerminal(a app.App, in io.Reader, out io.Writer,
+	tickerFactory func(d time.Duration) (<-chan time.Time, func())) *terminalTray {
+	return &terminalTray{
+		app:           a,
+		in:            in,
+		out:           out,
+		tickerFactory: tickerFactory,
+		closed:        make(chan struct{}),
+	}
+}
+
+// Run draws the status line and handles keys until q is pressed, Close
+// is called or ctx is done. Quitting with q shuts the app down. The
+// goroutine reading in stays blocked until the next key or the end of
+// the input.
+func (t *terminalTray) Run(ctx context.Context) error {
+	if f, ok := t.in.(*os.File); ok {
+		if restore, err := makeRaw(int(f.Fd())); err == nil {
+			defer restore()
+		}
+	}
+	t.mu.Lock()
+	fmt.Fprint(t.out, ansiHideCursor+terminalHelp+"\r\n")
+	t.mu.Unlock()
+	defer func() {
+		t.draw()
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.line = ""
+		fmt.Fprint(t.out, "\r\n"+ansiShowCursor)
+	}()
+
+	keys := make(chan byte)
+	go t.readKeys(keys)
+
+	redraw := make(chan struct{}, 1)
+	unsubscribe := t.app.SubscribeEvents(func(app.Event) {
+		select {
+		case redraw <- struct{}{}:
+		default:
+		}
+	})
+	defer unsubscribe()
+
+	tick, stop := t.tickerFactory(terminalRefreshInterval)
+	defer stop()
+
+	t.draw()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.closed:
+			return nil
+		case <-tick:
+			t.draw()
+		case <-redraw:
+			t.draw()
+		case k, ok := <-keys:
+			if !ok {
+				// end of input: keep showing the countdown
+				keys = nil
+				continue
+			}
+			if t.handleKey(k) {
+				return nil
+			}
+		}
+	}
+}
+
+// Close requests Run to return. It is idempotent.
+func (t *terminalTray) Close() error {
+	t.closeOnce.Do(func() { close(t.closed) })
+	return nil
+}
+
+// readKeys sends every byte read from in to keys and closes keys at the
+// end of the input.
+func (t *terminalTray) readKeys(keys chan<- byte) {
+	defer close(keys)
+	buf := make([]byte, 1)
+	for {
+		n, err := t.in.Read(buf)
+		if n > 0 {
+			keys <- buf[0]
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// handleKey runs the command bound to k and reports whether the user
+// asked to quit.
+func (t *terminalTray) handleKey(k byte) (quit bool) {
+	switch k {
+	case 'p':
+		t.app.StartPomodoro()
+	case 's':
+		t.app.StartShortBreak()
+	case 'l':
+		t.app.StartLongBreak()
+	case ' ':
+		if t.app.State() == app.StatePaused {
+			t.app.Resume()
+		} else {
+			t.app.Pause()
+		}
+	case 'q', 0x03: // raw mode delivers Ctrl-C as a key
+		log.Println("action=Quit")
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = t.app.Shutdown(ctx)
+		return true
+	}
+	return false
+}
+
+// draw replaces the status line with the current session.
+func (t *terminalTray) draw() {
+	line := statusLine(t.app.Snapshot())
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.line = line
+	fmt.Fprint(t.out, ansiClearLine+line)
+}
+
+// Write prints p above the status line and redraws the line below it,
+// so the log can share the terminal: pass the tray to `log.SetOutput`.
+func (t *terminalTray) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	text := strings.TrimSuffix(string(p), "\n")
+	text = strings.ReplaceAll(text, "\n", "\r\n")
+	if _, err := fmt.Fprint(t.out, ansiClearLine+text+"\r\n"+t.line); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// statusLine renders snap as the terminal status line, for example
+// "Pomodoro  12:05  [#########-----------]  48%  cycle 1/4  ABC-123".
+func statusLine(snap app.Snapshot) string {
+	name := "Idle"
+	if snap.Kind != "" {
+		name = kindName(snap.Kind)
+	}
+	if snap.State == app.StatePaused {
+		name += " (paused)"
+	}
+
+	// integer arithmetic keeps whole percentages exact
+	var elapsed, planned time.Duration = 0, 1
+	if snap.State != app.StateIdle && snap.Planned > 0 {
+		elapsed, planned = snap.Planned-snap.Remaining, snap.Planned
+		if elapsed < 0 {
+			elapsed = 0
+		}
+	}
+	filled := int(elapsed * progressWidth / planned)
+	bar := strings.Repeat("#", filled) + strings.Repeat("-", progressWidth-filled)
+
+	countdown := "--:--"
+	if snap.State != app.StateIdle {
+		countdown = formatCountdown(snap.Remaining)
+	}
+
+	line := fmt.Sprintf("%s%s%s  %s  [%s]  %3d%%  cycle %s",
+		ansiBold, name, ansiReset, countdown, bar, int(elapsed*100/planned), snap.Cycle)
+	if snap.Task != "" {
+		line += "  " + snap.Task
+	}
+	return line
+}
+
+// formatCountdown renders d as minutes and seconds, rounding partial
+// seconds up so a fresh 25 minute pomodoro shows "25:00".
+func formatCountdown(d time.Duration) string {
+	secs := int((d + time.Second - 1) / time.Second)
+	return fmt.Sprintf("%02d:%02d", secs/60, secs%60)
+}
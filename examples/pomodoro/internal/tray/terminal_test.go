@@ -0,0 +1,149 @@
+package tray
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+	"github.com/co0p/4dc/examples/pomodoro/internal/apptest"
+	"github.com/co0p/4dc/examples/pomodoro/internal/clock"
+)
+
+func startTerminal(t *testing.T) (a app.App, fc *clock.Fake, keys io.Writer, out *apptest.SyncBuffer, done <-chan error) {
+	t.Helper()
+	a, fc = apptest.NewApp(t)
+	pr, pw := io.Pipe()
+	t.Cleanup(func() { pw.Close() })
+	out = &apptest.SyncBuffer{}
+	tt := newTerminal(a, pr, out, ClockTicker(fc))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	errc := make(chan error, 1)
+	go func() { errc <- tt.Run(ctx) }()
+	return a, fc, pw, out, errc
+}
+
+func TestTerminalKeysControlTheApp(t *testing.T) {
+	a, _, keys, _, done := startTerminal(t)
+
+	steps := []struct {
+		key  string
+		want app.State
+		kind app.Kind
+	}{
+		{"p", app.StatePomodoroRunning, app.KindPomodoro},
+		{" ", app.StatePaused, app.KindPomodoro},
+		{" ", app.StatePomodoroRunning, app.KindPomodoro},
+		{"s", app.StateBreakRunning, app.KindShortBreak},
+		{"p", app.StatePomodoroRunning, app.KindPomodoro},
+		{"l", app.StateBreakRunning, app.KindLongBreak},
+	}
+	for _, s := range steps {
+		if _, err := io.WriteString(keys, s.key); err != nil {
+			t.Fatal(err)
+		}
+		apptest.Eventually(t, "key "+s.key, func() bool { return a.State() == s.want && a.Kind() == s.kind })
+	}
+
+	io.WriteString(keys, "q")
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run returned %v after q, want nil", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after q")
+	}
+	if a.State() != app.StateIdle {
+		t.Fatalf("state after quit = %s, want idle", a.State())
+	}
+}
+
+func TestTerminalRendersCountdown(t *testing.T) {
+	a, fc, keys, out, _ := startTerminal(t)
+
+	io.WriteString(keys, "p")
+	apptest.Eventually(t, "pomodoro", func() bool { return a.State() == app.StatePomodoroRunning })
+	apptest.Eventually(t, "initial countdown", func() bool { return strings.Contains(out.String(), "25:00") })
+
+	fc.Advance(5 * time.Minute)
+	apptest.Eventually(t, "countdown after 5 minutes", func() bool {
+		return strings.Contains(out.String(), "20:00  [####----------------]   20%  cycle 0/4")
+	})
+	if !strings.Contains(out.String(), ansiClearLine) {
+		t.Fatal("status line is not redrawn in place")
+	}
+}
+
+func TestTerminalCloseStopsRun(t *testing.T) {
+	a, _ := app.NewWithOptions()
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	tt := newTerminal(a, pr, io.Discard, ClockTicker(clock.Real()))
+
+	done := make(chan error, 1)
+	go func() { done <- tt.Run(context.Background()) }()
+	tt.Close()
+	tt.Close()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run returned %v after Close, want nil", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after Close")
+	}
+}
+
+func TestStatusLine(t *testing.T) {
+	tests := []struct {
+		name string
+		snap app.Snapshot
+		want string
+	}{
+		{
+			name: "idle",
+			snap: app.Snapshot{State: app.StateIdle, Cycle: app.Cycle{Length: 4}},
+			want: "Idle  --:--  [--------------------]    0%  cycle 0/4",
+		},
+		{
+			name: "paused pomodoro with task",
+			snap: app.Snapshot{State: app.StatePaused, Kind: app.KindPomodoro, Planned: 25 * time.Minute,
+				Remaining: 12*time.Minute + 30*time.Second, Cycle: app.Cycle{Completed: 1, Length: 4}, Task: "ABC-123"},
+			want: "Pomodoro (paused)  12:30  [##########----------]   50%  cycle 1/4  ABC-123",
+		},
+		{
+			name: "partial seconds round up",
+			snap: app.Snapshot{State: app.StateBreakRunning, Kind: app.KindShortBreak, Planned: 5 * time.Minute,
+				Remaining: 5*time.Minute - time.Millisecond, Cycle: app.Cycle{Completed: 1, Length: 4}},
+			want: "Short break  05:00  [--------------------]    0%  cycle 1/4",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.NewReplacer(ansiBold, "", ansiReset, "").Replace(statusLine(tt.snap))
+			if got != tt.want {
+				t.Fatalf("statusLine = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTerminalWritePrintsAboveStatusLine(t *testing.T) {
+	a, _ := app.NewWithOptions()
+	out := &apptest.SyncBuffer{}
+	tt := newTerminal(a, strings.NewReader(""), out, ClockTicker(clock.Real()))
+	tt.draw()
+
+	tt.Write([]byte("pomodoro: config reloaded\n"))
+
+	line := statusLine(a.Snapshot())
+	want := ansiClearLine + line + ansiClearLine + "pomodoro: config reloaded\r\n" + line
+	if got := out.String(); got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,46 @@
+package tray
+
+import (
+	"strings"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+)
+
+// Tooltip describes the current session of a for the tray tooltip, for
+// example "Pomodoro: ABC-123 · 1 internal, 0 external · cycle 1/4" or
+// "Paused: Short break · cycle 2/4". Interruptions are listed once there
+// are any.
+func Tooltip(a app.App) string {
+	var parts []string
+	head := "Idle"
+	if k := a.Kind(); k != "" {
+		head = kindName(k)
+		if task := a.Task(); task != "" {
+			head += ": " + task
+		}
+		if a.State() == app.StatePaused {
+			head = "Paused: " + head
+		}
+	}
+	parts = append(parts, head)
+	if i := a.Interruptions(); i.Total() > 0 {
+		parts = append(parts, i.String())
+	}
+	if c := a.Cycle(); c.Length > 0 {
+		parts = append(parts, "cycle "+c.String())
+	}
+	return strings.Join(parts, " · ")
+}
+
+// kindName returns the menu name of a session kind.
+func kindName(k app.Kind) string {
+	switch k {
+	case app.KindPomodoro:
+		return "Pomodoro"
+	case app.KindShortBreak:
+		return "Short break"
+	case app.KindLongBreak:
+		return "Long break"
+	}
+	return string(k)
+}
@@ -0,0 +1,30 @@
+package tray
+
+import (
+	"testing"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+	"github.com/co0p/4dc/examples/pomodoro/internal/apptest"
+)
+
+func TestTooltip(t *testing.T) {
+	a, c := apptest.NewApp(t)
+
+	if got := Tooltip(a); got != "Idle · cycle 0/4" {
+		t.Fatalf("unexpected idle tooltip %q", got)
+	}
+	a.StartPomodoroFor("ABC-123")
+	if got := Tooltip(a); got != "Pomodoro: ABC-123 · cycle 0/4" {
+		t.Fatalf("unexpected running tooltip %q", got)
+	}
+	_ = a.RecordInterruption(app.InterruptionExternal)
+	if got := Tooltip(a); got != "Pomodoro: ABC-123 · 0 internal, 1 external · cycle 0/4" {
+		t.Fatalf("unexpected interrupted tooltip %q", got)
+	}
+	c.Advance(app.DefaultPomodoro)
+	a.StartShortBreak()
+	a.Pause()
+	if got := Tooltip(a); got != "Paused: Short break · cycle 1/4" {
+		t.Fatalf("unexpected paused tooltip %q", got)
+	}
+}
@@ -2,10 +2,14 @@ package tray
 
 import (
 	"context"
+	"time"
 
 	"github.com/co0p/4dc/examples/pomodoro/internal/app"
 )
 
+// ExtendStep is the time the "+5 min" menu item adds to the session.
+const ExtendStep = 5 * time.Minute
+
 // Tray abstracts a platform-specific tray/menu implementation.
 type Tray interface {
 	// Run starts the tray UI and blocks until it exits or context is canceled.
@@ -14,7 +18,20 @@ type Tray interface {
 	Close() error
 }
 
+// TitleConfigurer is implemented by trays whose title settings can be
+// changed while they run, for example after a configuration reload.
+type TitleConfigurer interface {
+	ApplyTitleSettings(ts TitleSettings) error
+}
+
+// RecentTaskSeeder is implemented by trays that offer recently used task
+// labels, so they can list tasks from earlier runs.
+type RecentTaskSeeder interface {
+	SeedRecentTasks(labels []string)
+}
+
 // NewSystray is implemented in systray_impl.go and returns a Tray backed by a systray package.
-func NewSystray(a app.App, icon []byte) Tray {
-	return newSystrayImpl(a, icon)
+// The title settings control the remaining-time label; see `TitleSettings`.
+func NewSystray(a app.App, icon []byte, title TitleSettings) Tray {
+	return newSystrayImpl(a, icon, title)
 }
@@ -3,21 +3,38 @@ package tray
 import (
 	"context"
 	"fmt"
+	"log"
 	"sync"
 	"time"
 
 	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+	"github.com/co0p/4dc/examples/pomodoro/internal/clock"
+	"github.com/co0p/4dc/examples/pomodoro/internal/status"
 )
 
 // titleUpdateInterval is the cadence at which the tray title is refreshed
 // when a session is running.
 const titleUpdateInterval = 10 * time.Second
 
+// DefaultTitleFormat renders the remaining whole minutes, for example "5m".
+const DefaultTitleFormat = "{{.Minutes}}m"
+
+// TitleSettings configures how the tray title is rendered and refreshed.
+// Zero fields use `DefaultTitleFormat` and a 10s refresh interval.
+type TitleSettings struct {
+	// Format is a text/template executed with the `status.Status` of the
+	// session, see `status.Template`.
+	Format string
+	// Interval is the refresh cadence while a session runs.
+	Interval time.Duration
+}
+
 // TitleUpdater manages the tray title lifecycle: it subscribes to app state
-// changes, updates the title immediately on transitions to running, and
-// periodically on a ticker. Stop() detaches subscriptions and stops the
-// ticker. The updater accepts an injected ticker factory to make tests
-// deterministic.
+// changes, updates the title immediately on transitions to running or when
+// the session is extended, and periodically on a ticker. While paused the
+// ticker is stopped and the title shows the frozen remaining time. Stop()
+// detaches subscriptions and stops the ticker. The updater accepts an
+// injected ticker factory to make tests deterministic.
 type TitleUpdater struct {
 	app           app.App
 	setTitle      func(string)
@@ -29,6 +46,10 @@ type TitleUpdater struct {
 	tickCh      <-chan time.Time
 	stopTicker  func()
 	running     bool
+	format      status.Formatter
+	interval    time.Duration
+	// applied signals Run that the title settings changed.
+	applied chan struct{}
 }
 
 // NewTitleUpdater constructs a TitleUpdater. The tickerFactory returns a
@@ -40,7 +61,54 @@ func NewTitleUpdater(a app.App, setTitle func(string), clearTitle func(),
 		setTitle:      setTitle,
 		clearTitle:    clearTitle,
 		tickerFactory: tickerFactory,
+		format:        mustTemplate(DefaultTitleFormat),
+		interval:      titleUpdateInterval,
+		applied:       make(chan struct{}, 1),
+	}
+}
+
+// Validate reports whether ts can be applied, so callers can check it
+// before changing anything else.
+func (ts TitleSettings) Validate() error {
+	_, err := ts.formatter()
+	return err
+}
+
+// formatter returns the title formatter of ts.
+func (ts TitleSettings) formatter() (status.Formatter, error) {
+	if ts.Format == "" {
+		ts.Format = DefaultTitleFormat
+	}
+	format, err := status.Template(ts.Format)
+	if err != nil {
+		return nil, fmt.Errorf("tray: title format: %w", err)
 	}
+	return format, nil
+}
+
+// Apply changes the title format and refresh interval. It may be called
+// before or while Run is active; a running updater re-renders the title
+// and restarts its ticker with the new interval. An invalid format is
+// rejected and the previous settings are kept.
+func (t *TitleUpdater) Apply(ts TitleSettings) error {
+	if ts.Interval <= 0 {
+		ts.Interval = titleUpdateInterval
+	}
+	format, err := ts.formatter()
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	t.format = format
+	t.interval = ts.Interval
+	t.mu.Unlock()
+
+	select {
+	case t.applied <- struct{}{}:
+	default:
+	}
+	return nil
 }
 
 // Run starts the updater loop and blocks until ctx is done. It subscribes to
@@ -48,17 +116,39 @@ func NewTitleUpdater(a app.App, setTitle func(string), clearTitle func(),
 func (t *TitleUpdater) Run(ctx context.Context) {
 	stateCh := make(chan app.State, 1)
 
-	unsub := t.app.SubscribeStateChange(func(s app.State) {
+	unsubState := t.app.SubscribeStateChange(func(s app.State) {
 		select {
 		case stateCh <- s:
 		default:
 		}
 	})
+	// an extended session keeps its state but has a new end time:
+	// re-deliver the state to refresh the title immediately
+	unsubEvents := t.app.SubscribeEvents(func(e app.Event) {
+		if e.Reason != app.ReasonExtended {
+			return
+		}
+		select {
+		case stateCh <- e.To:
+		default:
+		}
+	})
 
 	t.mu.Lock()
-	t.unsubscribe = unsub
+	t.unsubscribe = func() {
+		unsubState()
+		unsubEvents()
+	}
 	t.mu.Unlock()
 
+	// show a session that was already active, for example a restored one
+	if s := t.app.State(); s != app.StateIdle {
+		select {
+		case stateCh <- s:
+		default:
+		}
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -82,16 +172,26 @@ func (t *TitleUpdater) Run(ctx context.Context) {
 				if t.stopTicker != nil {
 					t.stopTicker()
 				}
-				ch, stopper := t.tickerFactory(titleUpdateInterval)
+				ch, stopper := t.tickerFactory(t.interval)
 				t.tickCh = ch
 				t.stopTicker = stopper
 				t.running = true
 				t.mu.Unlock()
 
 				// immediate update
-				rem := t.app.Remaining()
-				mins := int(rem.Minutes())
-				t.setTitle(formatMinutes(mins))
+				t.setTitle(t.title())
+			} else if s == app.StatePaused {
+				// remaining time is frozen: stop ticking until resumed
+				t.mu.Lock()
+				if t.stopTicker != nil {
+					t.stopTicker()
+					t.stopTicker = nil
+				}
+				t.tickCh = nil
+				t.running = true
+				t.mu.Unlock()
+
+				t.setTitle(formatPaused(t.title()))
 			} else if s == app.StateIdle {
 				t.mu.Lock()
 				if t.running {
@@ -111,9 +211,15 @@ func (t *TitleUpdater) Run(ctx context.Context) {
 			running := t.running
 			t.mu.Unlock()
 			if running {
-				rem := t.app.Remaining()
-				mins := int(rem.Minutes())
-				t.setTitle(formatMinutes(mins))
+				t.setTitle(t.title())
+			}
+		case <-t.applied:
+			// re-deliver the current state so the new settings take effect
+			if s := t.app.State(); s != app.StateIdle {
+				select {
+				case stateCh <- s:
+				default:
+				}
 			}
 		}
 	}
@@ -140,6 +246,15 @@ func (t *TitleUpdater) Stop() {
 	}
 }
 
+// ClockTicker returns a ticker factory for `NewTitleUpdater` backed by c,
+// so the updater can share the clock that drives the app.
+func ClockTicker(c clock.Clock) func(d time.Duration) (<-chan time.Time, func()) {
+	return func(d time.Duration) (<-chan time.Time, func()) {
+		t := c.NewTicker(d)
+		return t.C(), t.Stop
+	}
+}
+
 // ManageTitleUpdates kept for compatibility: it constructs a TitleUpdater
 // and runs it until the context is cancelled.
 func ManageTitleUpdates(ctx context.Context, a app.App, setTitle func(string), clearTitle func(),
@@ -148,6 +263,34 @@ func ManageTitleUpdates(ctx context.Context, a app.App, setTitle func(string), c
 	u.Run(ctx)
 }
 
+// title renders the title for the current session with the configured
+// format. If the template fails it falls back to the remaining minutes.
+func (t *TitleUpdater) title() string {
+	st := status.Of(t.app)
+	t.mu.Lock()
+	format := t.format
+	t.mu.Unlock()
+
+	title, err := format(st)
+	if err != nil {
+		log.Printf("tray: title format: %v", err)
+		return formatMinutes(st.Minutes())
+	}
+	return title
+}
+
+func mustTemplate(text string) status.Formatter {
+	f, err := status.Template(text)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
 func formatMinutes(m int) string {
 	return fmt.Sprintf("%dm", m)
 }
+
+func formatPaused(title string) string {
+	return "⏸ " + title
+}
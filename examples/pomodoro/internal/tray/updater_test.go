@@ -2,40 +2,91 @@ package tray
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+	"github.com/co0p/4dc/examples/pomodoro/internal/apptest"
+	"github.com/co0p/4dc/examples/pomodoro/internal/clock"
 )
 
+// fakeApp is an app.App whose state changes are driven by the test
+// through emit. It is safe for concurrent use.
 type fakeApp struct {
-	rem   time.Duration
-	cb    func(app.State)
+	mu  sync.Mutex
+	rem time.Duration
+	cb  func(app.State)
+	// wired, when set, receives a value on every subscription; give it
+	// a buffer of one.
 	wired chan struct{}
 }
 
+// setRemaining changes what Remaining reports.
+func (f *fakeApp) setRemaining(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rem = d
+}
+
+// emit delivers s to the subscriber, if any.
+func (f *fakeApp) emit(s app.State) {
+	f.mu.Lock()
+	cb := f.cb
+	f.mu.Unlock()
+	if cb != nil {
+		cb(s)
+	}
+}
+
 func (f *fakeApp) StartPomodoro()                     {}
+func (f *fakeApp) StartPomodoroFor(task string)       {}
 func (f *fakeApp) StartBreak()                        {}
 func (f *fakeApp) StartShortBreak()                   {}
 func (f *fakeApp) StartLongBreak()                    {}
+func (f *fakeApp) Pause()                             {}
+func (f *fakeApp) Resume()                            {}
+func (f *fakeApp) Extend(d time.Duration)             {}
+func (f *fakeApp) CompleteNow()                       {}
+func (f *fakeApp) Skip()                              {}
 func (f *fakeApp) Shutdown(ctx context.Context) error { return nil }
-func (f *fakeApp) OnStateChange(fn func(app.State))   { f.cb = fn }
+func (f *fakeApp) OnStateChange(fn func(app.State))   { f.SubscribeStateChange(fn) }
 func (f *fakeApp) SubscribeStateChange(fn func(app.State)) func() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	f.cb = fn
-	// signal that subscription is wired
-	if f.wired != nil {
-		close(f.wired)
-		f.wired = nil
+	select {
+	case f.wired <- struct{}{}:
+	default:
+	}
+	return func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.cb = nil
 	}
-	return func() { f.cb = nil }
 }
-func (f *fakeApp) State() app.State         { return app.StateIdle }
-func (f *fakeApp) Remaining() time.Duration { return f.rem }
+func (f *fakeApp) SubscribeEvents(fn func(app.Event)) func()       { return func() {} }
+func (f *fakeApp) State() app.State                                { return app.StateIdle }
+func (f *fakeApp) Kind() app.Kind                                  { return "" }
+func (f *fakeApp) Task() string                                    { return "" }
+func (f *fakeApp) RecordInterruption(k app.InterruptionKind) error { return nil }
+func (f *fakeApp) Interruptions() app.Interruptions                { return app.Interruptions{} }
+func (f *fakeApp) Cycle() app.Cycle                                { return app.Cycle{} }
+func (f *fakeApp) Remaining() time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rem
+}
+func (f *fakeApp) Snapshot() app.Snapshot {
+	return app.Snapshot{State: app.StateIdle, Remaining: f.Remaining()}
+}
+func (f *fakeApp) Restore(s app.Snapshot) error  { return nil }
+func (f *fakeApp) UpdateSettings(s app.Settings) {}
 
 // TestTitleUpdaterDeterministic verifies TitleUpdater updates the title
 // immediately on transition to running, on ticks, and clears on idle.
 func TestTitleUpdaterDeterministic(t *testing.T) {
-	f := &fakeApp{rem: 5 * time.Minute, wired: make(chan struct{})}
+	f := &fakeApp{rem: 5 * time.Minute, wired: make(chan struct{}, 1)}
 
 	titleCh := make(chan string, 10)
 	setTitle := func(s string) { titleCh <- s }
@@ -62,8 +113,8 @@ func TestTitleUpdaterDeterministic(t *testing.T) {
 	}
 
 	// transition to running -> immediate update
-	f.rem = 5 * time.Minute
-	f.cb(app.StatePomodoroRunning)
+	f.setRemaining(5 * time.Minute)
+	f.emit(app.StatePomodoroRunning)
 
 	select {
 	case got := <-titleCh:
@@ -75,7 +126,7 @@ func TestTitleUpdaterDeterministic(t *testing.T) {
 	}
 
 	// simulate a tick with updated remaining
-	f.rem = 4 * time.Minute
+	f.setRemaining(4 * time.Minute)
 	if currentTickCh == nil {
 		t.Fatal("no tick channel available")
 	}
@@ -90,7 +141,7 @@ func TestTitleUpdaterDeterministic(t *testing.T) {
 	}
 
 	// transition to idle -> clear
-	f.cb(app.StateIdle)
+	f.emit(app.StateIdle)
 	select {
 	case got := <-titleCh:
 		if got != "CLEAR" {
@@ -105,7 +156,7 @@ func TestTitleUpdaterDeterministic(t *testing.T) {
 }
 
 func TestTitleUpdaterStopUnsubscribes(t *testing.T) {
-	f := &fakeApp{rem: 3 * time.Minute, wired: make(chan struct{})}
+	f := &fakeApp{rem: 3 * time.Minute, wired: make(chan struct{}, 1)}
 
 	titleCh := make(chan string, 10)
 	setTitle := func(s string) { titleCh <- s }
@@ -130,7 +181,7 @@ func TestTitleUpdaterStopUnsubscribes(t *testing.T) {
 	}
 
 	// transition to running -> immediate update
-	f.cb(app.StatePomodoroRunning)
+	f.emit(app.StatePomodoroRunning)
 	select {
 	case got := <-titleCh:
 		if got != "3m" {
@@ -152,9 +203,7 @@ func TestTitleUpdaterStopUnsubscribes(t *testing.T) {
 	}
 
 	// attempt to trigger state change after stop; should not produce titles
-	if f.cb != nil {
-		f.cb(app.StatePomodoroRunning)
-	}
+	f.emit(app.StatePomodoroRunning)
 	select {
 	case got := <-titleCh:
 		t.Fatalf("unexpected title after stop: %q", got)
@@ -164,7 +213,7 @@ func TestTitleUpdaterStopUnsubscribes(t *testing.T) {
 }
 
 func TestTitleUpdaterStartStopCycles(t *testing.T) {
-	f := &fakeApp{rem: 2 * time.Minute, wired: make(chan struct{})}
+	f := &fakeApp{rem: 2 * time.Minute, wired: make(chan struct{}, 1)}
 
 	titleCh := make(chan string, 20)
 	setTitle := func(s string) { titleCh <- s }
@@ -193,8 +242,8 @@ func TestTitleUpdaterStartStopCycles(t *testing.T) {
 	cycles := 3
 	for i := 0; i < cycles; i++ {
 		// start running -> immediate update
-		f.rem = time.Duration(2-i) * time.Minute
-		f.cb(app.StatePomodoroRunning)
+		f.setRemaining(time.Duration(2-i) * time.Minute)
+		f.emit(app.StatePomodoroRunning)
 		select {
 		case got := <-titleCh:
 			if got == "CLEAR" {
@@ -227,9 +276,7 @@ func TestTitleUpdaterStartStopCycles(t *testing.T) {
 		}
 
 		// ensure no title after external state change
-		if f.cb != nil {
-			f.cb(app.StatePomodoroRunning)
-		}
+		f.emit(app.StatePomodoroRunning)
 		select {
 		case got := <-titleCh:
 			t.Fatalf("unexpected title after stop on cycle %d: %q", i, got)
@@ -238,7 +285,6 @@ func TestTitleUpdaterStartStopCycles(t *testing.T) {
 		}
 
 		// recreate updater for next cycle
-		f.wired = make(chan struct{})
 		u = NewTitleUpdater(f, setTitle, clearTitle, newTicker)
 		go u.Run(ctx)
 
@@ -252,3 +298,207 @@ func TestTitleUpdaterStartStopCycles(t *testing.T) {
 
 	cancel()
 }
+
+func TestTitleUpdaterPausedFreezesTitle(t *testing.T) {
+	f := &fakeApp{rem: 7 * time.Minute, wired: make(chan struct{}, 1)}
+
+	titleCh := make(chan string, 10)
+	setTitle := func(s string) { titleCh <- s }
+	clearTitle := func() { titleCh <- "CLEAR" }
+
+	stopped := make(chan struct{}, 1)
+	newTicker := func(d time.Duration) (<-chan time.Time, func()) {
+		return make(chan time.Time), func() { stopped <- struct{}{} }
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	u := NewTitleUpdater(f, setTitle, clearTitle, newTicker)
+	go u.Run(ctx)
+
+	select {
+	case <-f.wired:
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("subscription not wired")
+	}
+
+	f.emit(app.StatePomodoroRunning)
+	select {
+	case got := <-titleCh:
+		if got != "7m" {
+			t.Fatalf("expected initial title 7m, got %q", got)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("timeout waiting for initial title")
+	}
+
+	// pause -> ticker stopped and paused title shown
+	f.emit(app.StatePaused)
+	select {
+	case got := <-titleCh:
+		if got != "⏸ 7m" {
+			t.Fatalf("expected paused title, got %q", got)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("timeout waiting for paused title")
+	}
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("expected ticker to be stopped on pause")
+	}
+
+	// idle after pause -> clear
+	f.emit(app.StateIdle)
+	select {
+	case got := <-titleCh:
+		if got != "CLEAR" {
+			t.Fatalf("expected clear, got %q", got)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("timeout waiting for clear title")
+	}
+}
+
+func TestTitleUpdaterWithFakeClockTicker(t *testing.T) {
+	f := &fakeApp{rem: 10 * time.Minute, wired: make(chan struct{}, 1)}
+	c := clock.NewFake(apptest.Epoch)
+
+	titleCh := make(chan string, 10)
+	setTitle := func(s string) { titleCh <- s }
+	clearTitle := func() { titleCh <- "CLEAR" }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	u := NewTitleUpdater(f, setTitle, clearTitle, ClockTicker(c))
+	go u.Run(ctx)
+
+	select {
+	case <-f.wired:
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("subscription not wired")
+	}
+
+	f.emit(app.StatePomodoroRunning)
+	select {
+	case got := <-titleCh:
+		if got != "10m" {
+			t.Fatalf("expected initial title 10m, got %q", got)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("timeout waiting for initial title")
+	}
+	if c.Pending() != 1 {
+		t.Fatalf("expected one ticker on the fake clock, got %d", c.Pending())
+	}
+
+	f.setRemaining(9 * time.Minute)
+	c.Advance(titleUpdateInterval)
+	select {
+	case got := <-titleCh:
+		if got != "9m" {
+			t.Fatalf("expected tick title 9m, got %q", got)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("timeout waiting for tick title")
+	}
+
+	f.emit(app.StateIdle)
+	<-titleCh
+	if c.Pending() != 0 {
+		t.Fatalf("expected ticker stopped on idle, got %d pending", c.Pending())
+	}
+}
+
+func TestTitleUpdaterApplyFormatAndInterval(t *testing.T) {
+	f := &fakeApp{rem: 90 * time.Second, wired: make(chan struct{}, 1)}
+
+	titleCh := make(chan string, 10)
+	setTitle := func(s string) { titleCh <- s }
+	clearTitle := func() { titleCh <- "CLEAR" }
+
+	intervals := make(chan time.Duration, 1)
+	newTicker := func(d time.Duration) (<-chan time.Time, func()) {
+		intervals <- d
+		return make(chan time.Time), func() {}
+	}
+
+	u := NewTitleUpdater(f, setTitle, clearTitle, newTicker)
+	if err := u.Apply(TitleSettings{Format: "{{.Minutes"}); err == nil {
+		t.Fatal("expected invalid format to be rejected")
+	}
+	if err := u.Apply(TitleSettings{Format: "🍅 {{.Remaining}} {{.Cycle}}", Interval: time.Second}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go u.Run(ctx)
+
+	select {
+	case <-f.wired:
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("subscription not wired")
+	}
+
+	f.emit(app.StatePomodoroRunning)
+	select {
+	case got := <-titleCh:
+		if got != "🍅 1m30s 0/0" {
+			t.Fatalf("unexpected formatted title %q", got)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("timeout waiting for title")
+	}
+	if d := <-intervals; d != time.Second {
+		t.Fatalf("expected ticker interval 1s, got %v", d)
+	}
+}
+
+func TestTitleUpdaterRefreshesOnExtend(t *testing.T) {
+	a, c := apptest.NewApp(t)
+
+	titleCh := make(chan string, 10)
+	setTitle := func(s string) { titleCh <- s }
+	clearTitle := func() { titleCh <- "CLEAR" }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a.StartPomodoro()
+	u := NewTitleUpdater(a, setTitle, clearTitle, ClockTicker(c))
+	go u.Run(ctx)
+
+	select {
+	case got := <-titleCh:
+		if got != "25m" {
+			t.Fatalf("expected initial title 25m, got %q", got)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("timeout waiting for initial title")
+	}
+
+	// the title follows the new end time without waiting for a tick
+	a.Extend(ExtendStep)
+	select {
+	case got := <-titleCh:
+		if got != "30m" {
+			t.Fatalf("expected extended title 30m, got %q", got)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("timeout waiting for extended title")
+	}
+}
+
+func TestTitleSettingsValidate(t *testing.T) {
+	if err := (TitleSettings{Format: "{{.Minutes"}).Validate(); err == nil {
+		t.Error("expected an invalid format to be reported")
+	}
+	for _, ts := range []TitleSettings{{}, {Format: "{{.Countdown}}", Interval: time.Second}} {
+		if err := ts.Validate(); err != nil {
+			t.Errorf("Validate(%+v): %v", ts, err)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+)
+
+// Payload is the JSON body posted for a state transition.
+type Payload struct {
+	// ID identifies the delivery; it stays the same across retries so
+	// receivers can drop duplicates.
+	ID     string     `json:"id"`
+	From   app.State  `json:"from"`
+	To     app.State  `json:"to"`
+	Reason app.Reason `json:"reason"`
+	Kind   app.Kind   `json:"kind,omitempty"`
+	Task   string     `json:"task,omitempty"`
+	At     time.Time  `json:"at"`
+	// Started and End describe the session; End is set while it runs.
+	Started        *time.Time `json:"started,omitempty"`
+	End            *time.Time `json:"end,omitempty"`
+	PlannedSeconds int        `json:"planned_seconds,omitempty"`
+	Cycle          Cycle      `json:"cycle"`
+}
+
+// Cycle is the progress through the pomodoro cycle after the transition.
+type Cycle struct {
+	Completed int `json:"completed"`
+	Length    int `json:"length"`
+}
+
+// NewPayload describes e, with c the cycle after the transition.
+func NewPayload(e app.Event, c app.Cycle) Payload {
+	p := Payload{
+		ID:             newID(),
+		From:           e.From,
+		To:             e.To,
+		Reason:         e.Reason,
+		Kind:           e.Kind,
+		Task:           e.Task,
+		At:             e.At,
+		PlannedSeconds: int(e.Planned / time.Second),
+		Cycle:          Cycle{Completed: c.Completed, Length: c.Length},
+	}
+	if !e.Started.IsZero() {
+		started := e.Started
+		p.Started = &started
+	}
+	if !e.End.IsZero() {
+		end := e.End
+		p.End = &end
+	}
+	return p
+}
+
+// Sign returns the signature header value for body: "sha256=" followed
+// by the hex HMAC-SHA256 of body keyed with secret.
+func Sign(secret string, body []byte) string {
+	m := hmac.New(sha256.New, []byte(secret))
+	m.Write(body)
+	return "sha256=" + hex.EncodeToString(m.Sum(nil))
+}
+
+// Verify reports whether signature is the signature of body for secret.
+// Receivers written in Go can use it to check deliveries.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
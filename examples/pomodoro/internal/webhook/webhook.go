@@ -0,0 +1,392 @@
+// Package webhook posts session transitions to HTTP endpoints. Every
+// state change is sent as a JSON `Payload` to each target, in order and
+// signed with the target's secret (see `Sign`). Failed deliveries are
+// retried with exponential backoff and kept in a bounded queue file, so
+// they survive restarts and offline periods.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+	"github.com/co0p/4dc/examples/pomodoro/internal/atomicfile"
+	"github.com/co0p/4dc/examples/pomodoro/internal/clock"
+)
+
+// Headers set on every delivery.
+const (
+	// SignatureHeader carries the HMAC of the body, see `Sign`.
+	SignatureHeader = "X-Pomodoro-Signature"
+	// DeliveryHeader carries the payload ID.
+	DeliveryHeader = "X-Pomodoro-Delivery"
+)
+
+const (
+	// DefaultMaxQueue bounds the deliveries kept per target; when it is
+	// exceeded the oldest delivery is dropped.
+	DefaultMaxQueue = 1000
+	// DefaultMinBackoff and DefaultMaxBackoff bound the wait before
+	// retrying a failed delivery. The wait doubles with every failure.
+	DefaultMinBackoff = time.Second
+	DefaultMaxBackoff = 5 * time.Minute
+	// requestTimeout bounds a single delivery attempt.
+	requestTimeout = 10 * time.Second
+)
+
+// Target is an endpoint receiving deliveries.
+type Target struct {
+	URL    string
+	Secret string
+}
+
+// Options configure a Sender. Zero fields use the defaults.
+type Options struct {
+	// QueueFile keeps undelivered payloads across restarts. Empty keeps
+	// them in memory only.
+	QueueFile  string
+	MaxQueue   int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	Client     *http.Client
+	Clock      clock.Clock
+	Logger     *log.Logger
+}
+
+// delivery is a payload waiting to be posted to a target.
+type delivery struct {
+	URL  string          `json:"url"`
+	ID   string          `json:"id"`
+	Body json.RawMessage `json:"body"`
+}
+
+// Sender delivers the transitions of an app to its targets.
+type Sender struct {
+	targets []Target
+	opts    Options
+
+	mu      sync.Mutex
+	pending map[string][]delivery // by target URL, oldest first
+	wake    map[string]chan struct{}
+	// dirty signals that the queue changed and should be saved.
+	dirty chan struct{}
+}
+
+// New returns a Sender for targets. Deliveries left in the queue file by
+// an earlier run are loaded and sent first; those for URLs that are no
+// longer targets are dropped.
+func New(targets []Target, o Options) *Sender {
+	if o.MaxQueue <= 0 {
+		o.MaxQueue = DefaultMaxQueue
+	}
+	if o.MinBackoff <= 0 {
+		o.MinBackoff = DefaultMinBackoff
+	}
+	if o.MaxBackoff < o.MinBackoff {
+		o.MaxBackoff = DefaultMaxBackoff
+	}
+	if o.Client == nil {
+		o.Client = &http.Client{Timeout: requestTimeout}
+	}
+	if o.Clock == nil {
+		o.Clock = clock.Real()
+	}
+	if o.Logger == nil {
+		o.Logger = log.Default()
+	}
+	s := &Sender{
+		targets: targets,
+		opts:    o,
+		pending: make(map[string][]delivery),
+		wake:    make(map[string]chan struct{}),
+		dirty:   make(chan struct{}, 1),
+	}
+	for _, t := range targets {
+		s.wake[t.URL] = make(chan struct{}, 1)
+	}
+	s.load()
+	return s
+}
+
+// Attach queues a delivery to every target on each state change of a
+// and sends them in the background until the returned function is
+// called. Undelivered payloads stay queued. The queue file is written
+// in the background, so the app never waits for the disk.
+func (s *Sender) Attach(a app.App) (detach func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.persist(ctx)
+	}()
+	for _, t := range s.targets {
+		wg.Add(1)
+		go func(t Target) {
+			defer wg.Done()
+			s.work(ctx, t)
+		}(t)
+	}
+
+	unsubscribe := a.SubscribeEvents(func(e app.Event) {
+		if !e.IsStateChange() {
+			return
+		}
+		p := NewPayload(e, a.Cycle())
+		body, err := json.Marshal(p)
+		if err != nil {
+			s.opts.Logger.Printf("webhook: %v", err)
+			return
+		}
+		for _, t := range s.targets {
+			s.enqueue(delivery{URL: t.URL, ID: p.ID, Body: body})
+		}
+	})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			unsubscribe()
+			cancel()
+			wg.Wait()
+			s.save()
+		})
+	}
+}
+
+// work posts the deliveries queued for t in order until ctx is done.
+// A failed delivery is retried after a backoff before any later one is
+// sent, so receivers see transitions in order.
+func (s *Sender) work(ctx context.Context, t Target) {
+	failures := 0
+	for {
+		d, ok := s.head(t.URL)
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.wake[t.URL]:
+			}
+			continue
+		}
+
+		err := s.post(ctx, t, d)
+		if ctx.Err() != nil {
+			// stopped during the attempt: the delivery stays queued
+			return
+		}
+		var perm *permanentError
+		switch {
+		case err == nil:
+			failures = 0
+			s.remove(t.URL, d.ID)
+		case errors.As(err, &perm):
+			failures = 0
+			s.opts.Logger.Printf("webhook %s: dropping delivery %s: %v", t.URL, d.ID, err)
+			s.remove(t.URL, d.ID)
+		default:
+			failures++
+			wait := s.backoff(failures)
+			s.opts.Logger.Printf("webhook %s: delivery %s failed: %v; retrying in %s", t.URL, d.ID, err, wait)
+			timer := s.opts.Clock.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C():
+			}
+		}
+	}
+}
+
+// backoff returns the wait after the given number of consecutive
+// failures: MinBackoff doubled for every failure after the first, at
+// most MaxBackoff.
+func (s *Sender) backoff(failures int) time.Duration {
+	d := s.opts.MinBackoff
+	for i := 1; i < failures && d < s.opts.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > s.opts.MaxBackoff {
+		d = s.opts.MaxBackoff
+	}
+	return d
+}
+
+// permanentError is a failure that retrying will not fix.
+type permanentError struct {
+	msg string
+}
+
+func (e *permanentError) Error() string {
+	return e.msg
+}
+
+// post sends d to t. Server errors, timeouts and rate limiting are
+// temporary; other 4xx responses are a *permanentError.
+func (s *Sender) post(ctx context.Context, t Target, d delivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.URL, bytes.NewReader(d.Body))
+	if err != nil {
+		return &permanentError{msg: err.Error()}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "pomodoro-webhook")
+	req.Header.Set(DeliveryHeader, d.ID)
+	req.Header.Set(SignatureHeader, Sign(t.Secret, d.Body))
+
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch code := resp.StatusCode; {
+	case code >= 200 && code < 300:
+		return nil
+	case code >= 400 && code < 500 && code != http.StatusRequestTimeout && code != http.StatusTooManyRequests:
+		return &permanentError{msg: fmt.Sprintf("rejected with status %d", code)}
+	default:
+		return fmt.Errorf("status %d", code)
+	}
+}
+
+// enqueue appends d to the queue of its target, dropping the oldest
+// delivery when the queue is full.
+func (s *Sender) enqueue(d delivery) {
+	s.mu.Lock()
+	q := append(s.pending[d.URL], d)
+	if len(q) > s.opts.MaxQueue {
+		s.opts.Logger.Printf("webhook %s: queue full, dropping delivery %s", d.URL, q[0].ID)
+		q = q[1:]
+	}
+	s.pending[d.URL] = q
+	s.mu.Unlock()
+	s.changed()
+
+	select {
+	case s.wake[d.URL] <- struct{}{}:
+	default:
+	}
+}
+
+// head returns the oldest delivery queued for url.
+func (s *Sender) head(url string) (delivery, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if q := s.pending[url]; len(q) > 0 {
+		return q[0], true
+	}
+	return delivery{}, false
+}
+
+// remove drops the delivery id from the queue of url. It may already be
+// gone when the queue overflowed during the attempt.
+func (s *Sender) remove(url, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q := s.pending[url]
+	for i, d := range q {
+		if d.ID == id {
+			s.pending[url] = append(q[:i:i], q[i+1:]...)
+			s.changed()
+			return
+		}
+	}
+}
+
+// Pending returns the number of queued deliveries for all targets.
+func (s *Sender) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, q := range s.pending {
+		n += len(q)
+	}
+	return n
+}
+
+// load reads the queue file.
+func (s *Sender) load() {
+	if s.opts.QueueFile == "" {
+		return
+	}
+	b, err := os.ReadFile(s.opts.QueueFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	var queued []delivery
+	if err == nil {
+		err = json.Unmarshal(b, &queued)
+	}
+	if err != nil {
+		s.opts.Logger.Printf("webhook: ignoring queue %s: %v", s.opts.QueueFile, err)
+		return
+	}
+	dropped := 0
+	for _, d := range queued {
+		if _, ok := s.wake[d.URL]; !ok {
+			dropped++
+			continue
+		}
+		s.pending[d.URL] = append(s.pending[d.URL], d)
+	}
+	if dropped > 0 {
+		s.opts.Logger.Printf("webhook: dropped %d queued deliveries for removed targets", dropped)
+	}
+	for url, q := range s.pending {
+		if len(q) > s.opts.MaxQueue {
+			s.pending[url] = q[len(q)-s.opts.MaxQueue:]
+		}
+	}
+}
+
+// changed schedules saving the queue file.
+func (s *Sender) changed() {
+	select {
+	case s.dirty <- struct{}{}:
+	default:
+	}
+}
+
+// persist saves the queue file after changes until ctx is done.
+func (s *Sender) persist(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.dirty:
+			s.save()
+		}
+	}
+}
+
+// save writes a copy of the queue to the queue file. Only one save runs
+// at a time: the persist goroutine, or detach once it has stopped.
+func (s *Sender) save() {
+	if s.opts.QueueFile == "" {
+		return
+	}
+	queued := []delivery{}
+	s.mu.Lock()
+	for _, t := range s.targets {
+		queued = append(queued, s.pending[t.URL]...)
+	}
+	s.mu.Unlock()
+	b, err := json.Marshal(queued)
+	if err == nil {
+		err = atomicfile.Write(s.opts.QueueFile, b, 0o600)
+	}
+	if err != nil {
+		s.opts.Logger.Printf("webhook: saving queue: %v", err)
+	}
+}
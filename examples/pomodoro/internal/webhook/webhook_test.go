@@ -0,0 +1,255 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/co0p/4dc/examples/pomodoro/internal/app"
+	"github.com/co0p/4dc/examples/pomodoro/internal/apptest"
+)
+
+const secret = "s3cret"
+
+// receiver is an httptest stand-in for a webhook endpoint. fail decides
+// the status of each request by its 1-based number.
+type receiver struct {
+	*httptest.Server
+	t    *testing.T
+	fail func(n int) int
+
+	mu       sync.Mutex
+	requests int
+	got      []Payload
+	ids      []string
+}
+
+func newReceiver(t *testing.T, fail func(n int) int) *receiver {
+	r := &receiver{t: t, fail: fail}
+	r.Server = httptest.NewServer(http.HandlerFunc(r.serve))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *receiver) serve(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests++
+	if r.fail != nil {
+		if code := r.fail(r.requests); code != 0 {
+			w.WriteHeader(code)
+			return
+		}
+	}
+	if !Verify(secret, body, req.Header.Get(SignatureHeader)) {
+		r.t.Errorf("bad signature %q", req.Header.Get(SignatureHeader))
+	}
+	var p Payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		r.t.Errorf("bad payload %s: %v", body, err)
+	}
+	if req.Header.Get(DeliveryHeader) != p.ID {
+		r.t.Errorf("delivery header %q does not match payload id %q", req.Header.Get(DeliveryHeader), p.ID)
+	}
+	r.got = append(r.got, p)
+}
+
+func (r *receiver) payloads() []Payload {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Payload(nil), r.got...)
+}
+
+func (r *receiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.requests
+}
+
+func quiet() *log.Logger { return log.New(io.Discard, "", 0) }
+
+func TestDeliversSignedTransitions(t *testing.T) {
+	r := newReceiver(t, nil)
+	a, c := apptest.NewApp(t)
+	s := New([]Target{{URL: r.URL, Secret: secret}}, Options{Clock: c, Logger: quiet()})
+	detach := s.Attach(a)
+	defer detach()
+
+	a.StartPomodoroFor("ABC-123")
+	a.Extend(5 * time.Minute) // not a state change
+	a.Pause()
+	apptest.Eventually(t, "two deliveries", func() bool { return len(r.payloads()) == 2 })
+
+	got := r.payloads()
+	start := got[0]
+	if start.From != app.StateIdle || start.To != app.StatePomodoroRunning || start.Reason != app.ReasonStarted ||
+		start.Kind != app.KindPomodoro || start.Task != "ABC-123" || start.PlannedSeconds != 1500 ||
+		start.End == nil || start.Cycle.Length != 4 {
+		t.Fatalf("unexpected start payload %+v", start)
+	}
+	if got[1].To != app.StatePaused || got[1].ID == start.ID {
+		t.Fatalf("unexpected pause payload %+v", got[1])
+	}
+}
+
+func TestRetriesWithBackoffInOrder(t *testing.T) {
+	// the first two attempts fail
+	r := newReceiver(t, func(n int) int {
+		if n <= 2 {
+			return http.StatusServiceUnavailable
+		}
+		return 0
+	})
+	a, c := apptest.NewApp(t)
+	s := New([]Target{{URL: r.URL, Secret: secret}}, Options{Clock: c, Logger: quiet(), MinBackoff: time.Second})
+	defer s.Attach(a)()
+
+	a.StartPomodoro()
+	a.Pause()
+	apptest.Eventually(t, "first backoff", func() bool { return c.Pending() == 1 })
+	c.Advance(time.Second)
+	apptest.Eventually(t, "second backoff", func() bool { return c.Pending() == 1 && r.count() == 2 })
+	c.Advance(time.Second)
+	if len(r.payloads()) != 0 {
+		t.Fatal("retried before the doubled backoff")
+	}
+	c.Advance(time.Second)
+	apptest.Eventually(t, "both deliveries", func() bool { return len(r.payloads()) == 2 })
+
+	got := r.payloads()
+	if got[0].To != app.StatePomodoroRunning || got[1].To != app.StatePaused {
+		t.Fatalf("deliveries out of order: %s, %s", got[0].To, got[1].To)
+	}
+	apptest.Eventually(t, "the queue to drain", func() bool { return s.Pending() == 0 })
+}
+
+func TestQueueSurvivesRestart(t *testing.T) {
+	queue := filepath.Join(t.TempDir(), "webhooks.json")
+	var mu sync.Mutex
+	offline := true
+	r := newReceiver(t, func(int) int {
+		mu.Lock()
+		defer mu.Unlock()
+		if offline {
+			return http.StatusServiceUnavailable
+		}
+		return 0
+	})
+	targets := []Target{{URL: r.URL, Secret: secret}}
+
+	a, c := apptest.NewApp(t)
+	detach := New(targets, Options{QueueFile: queue, Clock: c, Logger: quiet()}).Attach(a)
+	a.StartPomodoro()
+	a.Skip()
+	apptest.Eventually(t, "a failed attempt", func() bool { return c.Pending() == 1 })
+	detach()
+
+	mu.Lock()
+	offline = false
+	mu.Unlock()
+
+	// the next run sends what the previous one could not
+	s := New(targets, Options{QueueFile: queue, Clock: c, Logger: quiet()})
+	if s.Pending() != 2 {
+		t.Fatalf("expected 2 queued deliveries after restart, got %d", s.Pending())
+	}
+	detach = s.Attach(a)
+	apptest.Eventually(t, "queued deliveries", func() bool { return len(r.payloads()) == 2 })
+	if got := r.payloads(); got[0].To != app.StatePomodoroRunning || got[1].To != app.StateIdle {
+		t.Fatalf("deliveries out of order: %s, %s", got[0].To, got[1].To)
+	}
+	apptest.Eventually(t, "an empty queue", func() bool { return s.Pending() == 0 })
+	detach()
+	if s := New(targets, Options{QueueFile: queue, Logger: quiet()}); s.Pending() != 0 {
+		t.Fatalf("queue file still holds %d deliveries", s.Pending())
+	}
+}
+
+func TestQueueIsBounded(t *testing.T) {
+	queue := filepath.Join(t.TempDir(), "webhooks.json")
+	r := newReceiver(t, func(int) int { return http.StatusBadGateway })
+	a, c := apptest.NewApp(t)
+	s := New([]Target{{URL: r.URL, Secret: secret}}, Options{QueueFile: queue, MaxQueue: 2, Clock: c, Logger: quiet()})
+	detach := s.Attach(a)
+
+	a.StartPomodoro()
+	a.Pause()
+	a.Resume()
+	if s.Pending() != 2 {
+		t.Fatalf("expected the queue to hold 2 deliveries, got %d", s.Pending())
+	}
+	reload := func() int {
+		return New([]Target{{URL: r.URL, Secret: secret}}, Options{QueueFile: queue, MaxQueue: 2, Clock: c, Logger: quiet()}).Pending()
+	}
+	apptest.Eventually(t, "the queue file to be saved", func() bool { return reload() == 2 })
+	detach()
+	if n := reload(); n != 2 {
+		t.Fatalf("expected the queue file to hold 2 deliveries after detaching, got %d", n)
+	}
+}
+
+func TestClientErrorsAreNotRetried(t *testing.T) {
+	r := newReceiver(t, func(n int) int {
+		if n == 1 {
+			return http.StatusBadRequest
+		}
+		return 0
+	})
+	a, c := apptest.NewApp(t)
+	var logs strings.Builder
+	var mu sync.Mutex
+	s := New([]Target{{URL: r.URL, Secret: secret}}, Options{Clock: c, Logger: log.New(lockedWriter{&mu, &logs}, "", 0)})
+	defer s.Attach(a)()
+
+	a.StartPomodoro()
+	a.Pause()
+	apptest.Eventually(t, "the second delivery", func() bool { return len(r.payloads()) == 1 })
+	if got := r.payloads()[0].To; got != app.StatePaused {
+		t.Fatalf("expected the rejected delivery to be dropped, got %s", got)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !strings.Contains(logs.String(), "rejected with status 400") {
+		t.Fatalf("expected the drop to be logged, got %q", logs.String())
+	}
+}
+
+type lockedWriter struct {
+	mu *sync.Mutex
+	w  io.Writer
+}
+
+func (l lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+func TestBackoffIsCapped(t *testing.T) {
+	s := New(nil, Options{MinBackoff: time.Second, MaxBackoff: 5 * time.Second})
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		if got := s.backoff(i + 1); got != w {
+			t.Errorf("backoff(%d) = %s, want %s", i+1, got, w)
+		}
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	sig := Sign(secret, body)
+	if !strings.HasPrefix(sig, "sha256=") || len(sig) != len("sha256=")+64 {
+		t.Fatalf("unexpected signature %q", sig)
+	}
+	if !Verify(secret, body, sig) || Verify("other", body, sig) || Verify(secret, []byte(`{"id":"2"}`), sig) {
+		t.Fatal("Verify does not match Sign")
+	}
+}